@@ -1,54 +1,250 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	netHTTP "net/http"
-	"time"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"news-app/internal/cache"
+	"news-app/internal/config"
+	"news-app/internal/domain"
+	"news-app/internal/health"
+	"news-app/internal/logging"
+	"news-app/internal/metrics"
 	"news-app/internal/parser"
+	"news-app/internal/scheduler"
+	"news-app/internal/search"
 	"news-app/internal/service"
+	"news-app/internal/store"
 	"news-app/internal/transport/http"
+	"news-app/internal/urlpolicy"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/jonboulle/clockwork"
 	"github.com/mmcdole/gofeed"
 )
 
-var (
-	//timeout on calls to rss feeds
-	timeout = 10
-	//ttlDuration is the time to live for cache entries
-	ttlDuration = 5 * time.Minute
-	//tickerDuration is the time between each cache evaluation
-	tickerDuration = 1 * time.Minute
-)
-
 func main() {
-	internalCache := cache.NewCache(
-		ttlDuration,
-		tickerDuration,
-		clockwork.NewRealClock(),
+	cfg, opts, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if opts.PrintConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := run(cfg); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run serves requests until the process is signalled to stop, background work is stopped and stores are closed before it returns
+func run(cfg config.Config) error {
+	// stop on ctrl-c or when the orchestrator asks us to during a deploy
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	clock := clockwork.NewRealClock()
+	registry := metrics.NewRegistry()
+
+	// anything logged outside a request, such as by the cache, goes through the default logger
+	logger := logging.New(os.Stdout)
+	logging.SetDefault(logger)
+
+	internalCache, err := newCache(cfg.Cache, clock)
+	if err != nil {
+		return err
+	}
+	defer internalCache.Close()
+	internalCache = metrics.InstrumentCache(internalCache, registry)
+
+	// feed URLs come from users so they are checked before they are fetched, and every address the client connects to is checked too
+	policy := &urlpolicy.Policy{
+		Schemes:              cfg.Parser.AllowedSchemes,
+		AllowPrivateNetworks: cfg.Parser.AllowPrivateNetworks,
+		AllowedHosts:         cfg.Parser.AllowedHosts,
+		DeniedHosts:          cfg.Parser.DeniedHosts,
+		MaxRedirects:         cfg.Parser.MaxRedirects,
+	}
+	// fetches are spread out per host so many feeds on one publisher do not overwhelm it
+	fetcher := metrics.InstrumentHostLimiter(
+		parser.NewHostLimiter(
+			parser.NewFetcher(
+				urlpolicy.NewClient(policy),
+				cfg.Parser.UserAgent,
+				int64(cfg.Parser.MaxBodyBytes),
+				hostHeaders(cfg.Parser.HostHeaders),
+			),
+			cfg.Parser.HostInterval,
+			cfg.Parser.HostBurst,
+			cfg.Parser.HostConcurrency,
+			cfg.Parser.MinBackoff,
+			cfg.Parser.MaxBackoff,
+			clock,
+		),
+		registry,
+		clock,
+	)
+	// failures that may pass are retried, and feeds that keep failing are left alone for a while
+	universalParser := metrics.InstrumentParser(
+		urlpolicy.Guard(
+			parser.NewCircuitBreaker(
+				parser.NewRetryingParser(
+					parser.NewParser(cfg.Parser.Timeout, fetcher, gofeed.NewParser()),
+					cfg.Parser.RetryTimeout,
+					cfg.Parser.RetryAttempts,
+					cfg.Parser.RetryMinDelay,
+					cfg.Parser.RetryMaxDelay,
+					clock,
+				),
+				cfg.Parser.BreakerThreshold,
+				cfg.Parser.BreakerCoolDown,
+				clock,
+			),
+			policy,
+		),
+		registry,
+		clock,
 	)
 
-	universalParser := parser.NewParser(
-		timeout,
-		gofeed.NewParser(),
+	feedStore, err := store.NewFileFeedStore(cfg.Store.FeedsPath)
+	if err != nil {
+		return err
+	}
+
+	articleStore, err := store.NewBoltArticleStore(cfg.Store.ArticlesPath)
+	if err != nil {
+		return err
+	}
+	defer articleStore.Close()
+
+	// rebuild the search index from every article collected by previous runs
+	index := search.NewIndex()
+	err = articleStore.ForEachFeed(func(feedURL string, articles []domain.Article) error {
+		index.Add(feedURL, articles)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	svc := metrics.InstrumentService(
+		service.NewService(
+			universalParser,
+			internalCache,
+			feedStore,
+			articleStore,
+			index,
+		),
+		registry,
+		clock,
 	)
+	defer svc.Close()
 
-	svc := service.NewService(
-		universalParser,
-		internalCache,
+	poller := scheduler.NewScheduler(
+		svc,
+		cfg.Refresh.Interval,
+		cfg.Refresh.Jitter,
+		cfg.Refresh.Ticker,
+		cfg.Refresh.Workers,
+		clock,
 	)
+	poller.Start(logging.NewContext(ctx, logger.With("component", "scheduler")))
+	defer poller.Stop()
 
-	handler := http.NewHandler(svc)
+	handler := http.NewHandler(svc, cfg.Server.AdminToken)
 	handler.ApplyRoutes()
+	handler.Use(logging.Middleware(logger, clock))
+	handler.Use(metrics.Middleware(registry, clock))
+	handler.Use(http.Compress(cfg.Server.CompressionMinSize))
+	handler.Handle("/metrics", registry).Methods(netHTTP.MethodGet)
+
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+	checker.Add("cache", internalCache.Ping)
+	checker.Add("scheduler", poller.Ping)
+	checker.Add("feed_store", feedStore.Ping)
+	checker.Add("article_store", articleStore.Ping)
+	for _, feedURL := range cfg.Health.CriticalFeeds {
+		checker.Add("feed:"+feedURL, health.FeedFreshness(internalCache, feedURL, cfg.Health.MaxFeedAge))
+	}
+	handler.HandleFunc("/healthz", health.Live).Methods(netHTTP.MethodGet)
+	handler.Handle("/readyz", checker).Methods(netHTTP.MethodGet)
 
 	server := netHTTP.Server{
 		Handler:      handler,
-		Addr:         "127.0.0.1:8080",
-		WriteTimeout: time.Duration(timeout) * time.Second,
-		ReadTimeout:  time.Duration(timeout) * time.Second,
+		Addr:         cfg.Server.Addr,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
 	}
 
-	log.Fatal(server.ListenAndServe())
+	logger.Info("shutting down, waiting for in-flight requests", "timeout", cfg.Server.ShutdownTimeout)
+
+	// a second signal skips the drain
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	return server.Shutdown(shutdownCtx)
+}
+
+// newCache creates the cache backend selected by the configuration
+func newCache(cfg config.Cache, clock clockwork.Clock) (cache.Cache, error) {
+	switch cfg.Backend {
+	case "memory":
+		return cache.NewCache(
+			cfg.TTL,
+			cfg.StaleGrace,
+			cfg.CleanupInterval,
+			cfg.MaxEntries,
+			cfg.MaxBytes,
+			clock,
+		), nil
+	case "redis":
+		return cache.NewRedisCache(
+			redis.NewClient(&redis.Options{Addr: cfg.RedisAddr}),
+			cfg.TTL,
+			cfg.StaleGrace,
+			clock,
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.Backend)
+	}
+}
+
+// hostHeaders turns the configured headers per host into http headers
+func hostHeaders(cfg map[string]map[string]string) map[string]netHTTP.Header {
+	headers := make(map[string]netHTTP.Header, len(cfg))
+	for host, values := range cfg {
+		header := make(netHTTP.Header, len(values))
+		for name, value := range values {
+			header.Set(name, value)
+		}
+		headers[host] = header
+	}
+	return headers
 }
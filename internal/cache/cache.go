@@ -2,6 +2,9 @@
 package cache
 
 import (
+	"container/list"
+	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -12,32 +15,78 @@ import (
 
 // Cache is an interface for interacting with a caching layer
 type Cache interface {
-	GetArticlesFromCache(url string) ([]domain.Article, bool)
+	GetArticlesFromCache(url string) ([]domain.Article, Status, time.Duration)
 	AddArticlesToCache(url string, articles []domain.Article)
+	DeleteArticlesFromCache(url string) bool
+	Keys() []string
+	GetEntry(url string) (domain.CacheEntry, bool)
+	Stats() Stats
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// Status describes the freshness of a cache entry
+type Status int
+
+const (
+	// Missing means there is no entry for the url
+	Missing Status = iota
+	// Fresh means the entry is younger than the ttl
+	Fresh
+	// Stale means the entry is older than the ttl but still within the grace window, it can be served while it is refreshed
+	Stale
+)
+
+// Stats describes how full the cache is and how often it has had to evict entries to stay within its limits
+type Stats struct {
+	Entries   int
+	Bytes     int
+	Evictions uint64
 }
 
 // cache is the internal representation of our cache
 type cache struct {
-	ttl   time.Duration
-	clock clockwork.Clock
+	ttl        time.Duration
+	grace      time.Duration
+	maxEntries int
+	maxBytes   int
+	clock      clockwork.Clock
+	stop       chan struct{}
+	stopOnce   sync.Once
+	stopped    chan struct{}
 
-	mutex          sync.RWMutex
-	feedToArticles map[string]cachedArticles
+	mutex          sync.Mutex
+	feedToArticles map[string]*list.Element
+	// lru orders entries from most to least recently used, each element holds a *cachedArticles
+	lru       *list.List
+	bytes     int
+	evictions uint64
 }
 
 type cachedArticles struct {
+	url      string
 	created  time.Time
 	articles []domain.Article
+	size     int
 }
 
 // NewCache is a constructor for a Cache
-// ttlDuration represents how long a cache entry should live
+// ttlDuration represents how long a cache entry is fresh
+// graceDuration represents how long an entry is kept as stale once the ttl has passed
 // tickerDuration represents how long between each cache evaluation
-func NewCache(ttlDuration, tickerDuration time.Duration, clock clockwork.Clock) Cache {
+// maxEntries and maxBytes limit the number of feeds and approximate size of the articles held, the least recently used feeds are evicted
+// to stay within them. A limit of 0 means unlimited
+func NewCache(ttlDuration, graceDuration, tickerDuration time.Duration, maxEntries, maxBytes int, clock clockwork.Clock) Cache {
 	cache := &cache{
 		ttl:            ttlDuration,
-		feedToArticles: make(map[string]cachedArticles),
+		grace:          graceDuration,
+		maxEntries:     maxEntries,
+		maxBytes:       maxBytes,
+		feedToArticles: make(map[string]*list.Element),
+		lru:            list.New(),
 		clock:          clock,
+		stop:           make(chan struct{}),
+		stopped:        make(chan struct{}),
 	}
 
 	ticker := clock.NewTicker(tickerDuration)
@@ -46,42 +95,184 @@ func NewCache(ttlDuration, tickerDuration time.Duration, clock clockwork.Clock)
 	return cache
 }
 
-// GetArticlesFromCache will return true if cache was a hit, along with the articles it found. It will return false on a miss and a nil slice
-func (c *cache) GetArticlesFromCache(url string) ([]domain.Article, bool) {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+// GetArticlesFromCache will return the articles it found along with whether they are Fresh or Stale, and how much longer Fresh articles
+// stay fresh. It will return Missing on a miss and a nil slice
+func (c *cache) GetArticlesFromCache(url string) ([]domain.Article, Status, time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-	v, ok := c.feedToArticles[url]
+	e, ok := c.feedToArticles[url]
 	if !ok {
-		return nil, ok
+		return nil, Missing, 0
 	}
+	v := e.Value.(*cachedArticles)
 
-	return v.articles, ok
+	age := c.clock.Since(v.created)
+	switch {
+	case age < c.ttl:
+		c.lru.MoveToFront(e)
+		return v.articles, Fresh, c.ttl - age
+	case age < c.ttl+c.grace:
+		c.lru.MoveToFront(e)
+		return v.articles, Stale, 0
+	default:
+		// the entry has expired but has not been cleaned up yet
+		return nil, Missing, 0
+	}
 }
 
-// AddArticlesToCache will add or overwrite article to cache
+// AddArticlesToCache will add or overwrite article to cache, evicting the least recently used feeds if the cache is over its limits.
+// Articles larger than the whole byte budget are not cached.
 func (c *cache) AddArticlesToCache(url string, articles []domain.Article) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	c.feedToArticles[url] = cachedArticles{
+	if e, ok := c.feedToArticles[url]; ok {
+		c.remove(e)
+	}
+
+	v := &cachedArticles{
+		url:      url,
 		created:  c.clock.Now(),
 		articles: articles,
+		size:     articlesSize(articles),
+	}
+	if c.maxBytes > 0 && v.size > c.maxBytes {
+		return
+	}
+
+	c.feedToArticles[url] = c.lru.PushFront(v)
+	c.bytes += v.size
+
+	for (c.maxEntries > 0 && c.lru.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.remove(c.lru.Back())
+		c.evictions++
+	}
+}
+
+// DeleteArticlesFromCache removes a feed from the cache, it returns false if the feed was not cached
+func (c *cache) DeleteArticlesFromCache(url string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.feedToArticles[url]
+	if !ok {
+		return false
+	}
+
+	c.remove(e)
+	return true
+}
+
+// Keys returns the url of every cached feed from most to least recently used
+func (c *cache) Keys() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]string, 0, c.lru.Len())
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(*cachedArticles).url)
+	}
+
+	return keys
+}
+
+// GetEntry returns metadata about a cached feed without counting as a use of it, it returns false if the feed is not cached
+func (c *cache) GetEntry(url string) (domain.CacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.feedToArticles[url]
+	if !ok {
+		return domain.CacheEntry{}, false
+	}
+	v := e.Value.(*cachedArticles)
+
+	return newCacheEntry(url, v.created, v.size, c.clock.Since(v.created), c.ttl), true
+}
+
+// Stats returns the current size of the cache and the number of entries evicted to stay within its limits
+func (c *cache) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return Stats{
+		Entries:   c.lru.Len(),
+		Bytes:     c.bytes,
+		Evictions: c.evictions,
+	}
+}
+
+func (c *cache) remove(e *list.Element) {
+	v := c.lru.Remove(e).(*cachedArticles)
+	delete(c.feedToArticles, v.url)
+	c.bytes -= v.size
+}
+
+func newCacheEntry(url string, created time.Time, size int, age, ttl time.Duration) domain.CacheEntry {
+	entry := domain.CacheEntry{
+		URL:        url,
+		Created:    created,
+		AgeSeconds: int64(age / time.Second),
+		Size:       size,
+		Stale:      age >= ttl,
+	}
+	if !entry.Stale {
+		entry.ExpiresInSeconds = int64((ttl - age) / time.Second)
+	}
+
+	return entry
+}
+
+// articleOverhead approximates the memory used by an article other than its strings
+const articleOverhead = 128
+
+// articlesSize approximates the memory used by articles, it only needs to be good enough to size the cache
+func articlesSize(articles []domain.Article) int {
+	size := 0
+	for _, a := range articles {
+		size += articleOverhead + len(a.ID) + len(a.Title) + len(a.Description) + len(a.Content) + len(a.URL) + len(a.Image.URL) + len(a.Image.Title)
+	}
+
+	return size
+}
+
+// Close stops the cleanup goroutine and waits for it to exit, it is safe to call more than once
+func (c *cache) Close() error {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+	<-c.stopped
+
+	return nil
+}
+
+// Ping reports whether the cleanup goroutine is still running, it stops once the cache is closed
+func (c *cache) Ping(context.Context) error {
+	select {
+	case <-c.stopped:
+		return errors.New("cache cleanup is not running")
+	default:
+		return nil
 	}
 }
 
-// cleanup will evaluate the cache every time the ttl has passed and delete any records that have existed longer than the ttl
+// cleanup will evaluate the cache every tick and delete any records that have existed longer than the ttl and grace window
+// until the cache is closed
 func (c *cache) cleanup(ticker clockwork.Ticker) {
+	defer close(c.stopped)
 	defer ticker.Stop()
 
 	for {
 		select {
+		case <-c.stop:
+			return
 		case <-ticker.Chan():
 			c.mutex.Lock()
-			for k, v := range c.feedToArticles {
+			for _, e := range c.feedToArticles {
 				// if the cache entry was created more than X ago
-				if c.clock.Since(v.created) >= c.ttl {
-					delete(c.feedToArticles, k)
+				if c.clock.Since(e.Value.(*cachedArticles).created) >= c.ttl+c.grace {
+					c.remove(e)
 				}
 			}
 			c.mutex.Unlock()
@@ -5,8 +5,10 @@
 package cache
 
 import (
+	context "context"
 	domain "news-app/internal/domain"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -46,13 +48,42 @@ func (mr *MockCacheMockRecorder) AddArticlesToCache(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddArticlesToCache", reflect.TypeOf((*MockCache)(nil).AddArticlesToCache), arg0, arg1)
 }
 
+// Close mocks base method.
+func (m *MockCache) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockCacheMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockCache)(nil).Close))
+}
+
+// DeleteArticlesFromCache mocks base method.
+func (m *MockCache) DeleteArticlesFromCache(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteArticlesFromCache", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// DeleteArticlesFromCache indicates an expected call of DeleteArticlesFromCache.
+func (mr *MockCacheMockRecorder) DeleteArticlesFromCache(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteArticlesFromCache", reflect.TypeOf((*MockCache)(nil).DeleteArticlesFromCache), arg0)
+}
+
 // GetArticlesFromCache mocks base method.
-func (m *MockCache) GetArticlesFromCache(arg0 string) ([]domain.Article, bool) {
+func (m *MockCache) GetArticlesFromCache(arg0 string) ([]domain.Article, Status, time.Duration) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetArticlesFromCache", arg0)
 	ret0, _ := ret[0].([]domain.Article)
-	ret1, _ := ret[1].(bool)
-	return ret0, ret1
+	ret1, _ := ret[1].(Status)
+	ret2, _ := ret[2].(time.Duration)
+	return ret0, ret1, ret2
 }
 
 // GetArticlesFromCache indicates an expected call of GetArticlesFromCache.
@@ -60,3 +91,60 @@ func (mr *MockCacheMockRecorder) GetArticlesFromCache(arg0 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticlesFromCache", reflect.TypeOf((*MockCache)(nil).GetArticlesFromCache), arg0)
 }
+
+// GetEntry mocks base method.
+func (m *MockCache) GetEntry(arg0 string) (domain.CacheEntry, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", arg0)
+	ret0, _ := ret[0].(domain.CacheEntry)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockCacheMockRecorder) GetEntry(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockCache)(nil).GetEntry), arg0)
+}
+
+// Keys mocks base method.
+func (m *MockCache) Keys() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Keys")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// Keys indicates an expected call of Keys.
+func (mr *MockCacheMockRecorder) Keys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Keys", reflect.TypeOf((*MockCache)(nil).Keys))
+}
+
+// Ping mocks base method.
+func (m *MockCache) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockCacheMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockCache)(nil).Ping), arg0)
+}
+
+// Stats mocks base method.
+func (m *MockCache) Stats() Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockCacheMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockCache)(nil).Stats))
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/assert"
 	"news-app/internal/domain"
@@ -28,48 +29,203 @@ func Test_Cache(t *testing.T) {
 		}
 		someArticles       = []domain.Article{someArticle}
 		someTTLDuration    = time.Duration(5) * time.Second
+		someGraceDuration  = time.Duration(10) * time.Second
 		someTickerDuration = time.Duration(1) * time.Second
 	)
 	t.Run("should return cache hit and articles", func(t *testing.T) {
-		cache := NewCache(someTTLDuration, someTickerDuration, clockwork.NewRealClock())
+		cache := NewCache(someTTLDuration, someGraceDuration, someTickerDuration, 0, 0, clockwork.NewRealClock())
+		defer cache.Close()
 
 		// add item to cache
 		cache.AddArticlesToCache(someURL, someArticles)
 
 		// get item from cache
-		articles, ok := cache.GetArticlesFromCache(someURL)
-		assert.True(t, ok)
+		articles, status, _ := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Fresh, status)
 		assert.Equal(t, articles, someArticles)
 	})
 	t.Run("should return cache miss and nil articles", func(t *testing.T) {
-		cache := NewCache(someTTLDuration, someTickerDuration, clockwork.NewRealClock())
+		cache := NewCache(someTTLDuration, someGraceDuration, someTickerDuration, 0, 0, clockwork.NewRealClock())
+		defer cache.Close()
 
 		// get item from cache
-		articles, ok := cache.GetArticlesFromCache(someURL)
-		assert.False(t, ok)
+		articles, status, _ := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Missing, status)
+		assert.Nil(t, articles)
+	})
+	t.Run("should return stale articles once ttl has passed until the grace window has passed", func(t *testing.T) {
+		clock := clockwork.NewFakeClock()
+		cache := NewCache(someTTLDuration, someGraceDuration, time.Hour, 0, 0, clock)
+		defer cache.Close()
+
+		cache.AddArticlesToCache(someURL, someArticles)
+
+		clock.Advance(someTTLDuration / 5)
+		_, status, freshFor := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Fresh, status)
+		assert.Equal(t, 4*someTTLDuration/5, freshFor)
+
+		clock.Advance(4 * someTTLDuration / 5)
+		articles, status, freshFor := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Stale, status)
+		assert.Zero(t, freshFor)
+		assert.Equal(t, articles, someArticles)
+
+		// the ticker has not fired so the entry is still stored but should not be served
+		clock.Advance(someGraceDuration)
+		articles, status, _ = cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Missing, status)
 		assert.Nil(t, articles)
 	})
+	t.Run("should return fresh articles once a stale entry is replaced", func(t *testing.T) {
+		clock := clockwork.NewFakeClock()
+		cache := NewCache(someTTLDuration, someGraceDuration, someTickerDuration, 0, 0, clock)
+		defer cache.Close()
+
+		cache.AddArticlesToCache(someURL, someArticles)
+		clock.Advance(someTTLDuration)
+		cache.AddArticlesToCache(someURL, someArticles)
+
+		_, status, _ := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Fresh, status)
+	})
 
-	t.Run("should remove items from cache once ttl has passed", func(t *testing.T) {
+	t.Run("should remove items from cache once ttl and grace window have passed", func(t *testing.T) {
 		clock := clockwork.NewFakeClock()
-		cache := NewCache(someTTLDuration, someTickerDuration, clock)
+		cache := NewCache(someTTLDuration, someGraceDuration, someTickerDuration, 0, 0, clock)
+		defer cache.Close()
 
 		// add item to cache
 		cache.AddArticlesToCache(someURL, someArticles)
-		articles, ok := cache.GetArticlesFromCache(someURL)
-		assert.True(t, ok)
+		articles, status, _ := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Fresh, status)
 		assert.Equal(t, articles, someArticles)
 
 		// advance clock and ticker so cache is cleaned up
-		clock.Advance(someTTLDuration)
+		clock.Advance(someTTLDuration + someGraceDuration)
 		clock.BlockUntil(1)
 
 		// block to allow cache to be cleared before trying to access
 		time.Sleep(10 * time.Millisecond)
 
 		// fail to get item from cache
-		articles, ok = cache.GetArticlesFromCache(someURL)
-		assert.False(t, ok)
+		articles, status, _ = cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Missing, status)
 		assert.Nil(t, articles)
 	})
+	t.Run("should evict the least recently used feed once there are too many entries", func(t *testing.T) {
+		cache := NewCache(someTTLDuration, someGraceDuration, someTickerDuration, 2, 0, clockwork.NewFakeClock())
+		defer cache.Close()
+
+		cache.AddArticlesToCache("some-first-url", someArticles)
+		cache.AddArticlesToCache("some-second-url", someArticles)
+
+		// reading the first feed makes the second the least recently used
+		_, status, _ := cache.GetArticlesFromCache("some-first-url")
+		assert.Equal(t, Fresh, status)
+
+		cache.AddArticlesToCache("some-third-url", someArticles)
+
+		_, status, _ = cache.GetArticlesFromCache("some-second-url")
+		assert.Equal(t, Missing, status)
+		_, status, _ = cache.GetArticlesFromCache("some-first-url")
+		assert.Equal(t, Fresh, status)
+		_, status, _ = cache.GetArticlesFromCache("some-third-url")
+		assert.Equal(t, Fresh, status)
+
+		assert.Equal(t, Stats{Entries: 2, Bytes: 2 * articlesSize(someArticles), Evictions: 1}, cache.Stats())
+	})
+	t.Run("should evict the least recently used feeds once the byte budget is exceeded", func(t *testing.T) {
+		size := articlesSize(someArticles)
+		cache := NewCache(someTTLDuration, someGraceDuration, someTickerDuration, 0, 2*size, clockwork.NewFakeClock())
+		defer cache.Close()
+
+		cache.AddArticlesToCache("some-first-url", someArticles)
+		cache.AddArticlesToCache("some-second-url", someArticles)
+		cache.AddArticlesToCache("some-third-url", append(someArticles, someArticles...))
+
+		_, status, _ := cache.GetArticlesFromCache("some-first-url")
+		assert.Equal(t, Missing, status)
+		_, status, _ = cache.GetArticlesFromCache("some-second-url")
+		assert.Equal(t, Missing, status)
+
+		assert.Equal(t, Stats{Entries: 1, Bytes: 2 * size, Evictions: 2}, cache.Stats())
+	})
+	t.Run("should not cache articles larger than the byte budget", func(t *testing.T) {
+		cache := NewCache(someTTLDuration, someGraceDuration, someTickerDuration, 0, articlesSize(someArticles)-1, clockwork.NewFakeClock())
+		defer cache.Close()
+
+		cache.AddArticlesToCache(someURL, someArticles)
+
+		_, status, _ := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Missing, status)
+		assert.Equal(t, Stats{}, cache.Stats())
+	})
+	t.Run("should account for entries that are replaced", func(t *testing.T) {
+		cache := NewCache(someTTLDuration, someGraceDuration, someTickerDuration, 1, 0, clockwork.NewFakeClock())
+		defer cache.Close()
+
+		cache.AddArticlesToCache(someURL, someArticles)
+		cache.AddArticlesToCache(someURL, someArticles)
+
+		assert.Equal(t, Stats{Entries: 1, Bytes: articlesSize(someArticles)}, cache.Stats())
+	})
+	t.Run("should delete, list and describe entries", func(t *testing.T) {
+		clock := clockwork.NewFakeClock()
+		cache := NewCache(someTTLDuration, someGraceDuration, someTickerDuration, 0, 0, clock)
+		defer cache.Close()
+
+		cache.AddArticlesToCache("some-first-url", someArticles)
+		created := clock.Now()
+		clock.Advance(someTTLDuration)
+		cache.AddArticlesToCache("some-second-url", someArticles)
+
+		assert.Equal(t, []string{"some-second-url", "some-first-url"}, cache.Keys())
+
+		entry, ok := cache.GetEntry("some-first-url")
+		assert.True(t, ok)
+		assert.Equal(t, domain.CacheEntry{
+			URL:        "some-first-url",
+			Created:    created,
+			AgeSeconds: 5,
+			Size:       articlesSize(someArticles),
+			Stale:      true,
+		}, entry)
+
+		entry, ok = cache.GetEntry("some-second-url")
+		assert.True(t, ok)
+		assert.Equal(t, int64(5), entry.ExpiresInSeconds)
+		assert.False(t, entry.Stale)
+
+		assert.True(t, cache.DeleteArticlesFromCache("some-first-url"))
+		assert.False(t, cache.DeleteArticlesFromCache("some-first-url"))
+
+		_, ok = cache.GetEntry("some-first-url")
+		assert.False(t, ok)
+		assert.Equal(t, []string{"some-second-url"}, cache.Keys())
+		assert.Equal(t, Stats{Entries: 1, Bytes: articlesSize(someArticles)}, cache.Stats())
+	})
+	t.Run("should stop removing items once closed", func(t *testing.T) {
+		clock := clockwork.NewFakeClock()
+		cache := NewCache(someTTLDuration, someGraceDuration, someTickerDuration, 0, 0, clock)
+
+		cache.AddArticlesToCache(someURL, someArticles)
+		assert.NoError(t, cache.Close())
+		assert.NoError(t, cache.Close())
+
+		clock.Advance(someTTLDuration + someGraceDuration)
+
+		// block to give a running cleanup the chance to clear the cache
+		time.Sleep(10 * time.Millisecond)
+
+		_, ok := cache.GetEntry(someURL)
+		assert.True(t, ok)
+	})
+	t.Run("should report whether cleanup is running", func(t *testing.T) {
+		cache := NewCache(someTTLDuration, someGraceDuration, someTickerDuration, 0, 0, clockwork.NewFakeClock())
+		assert.NoError(t, cache.Ping(context.Background()))
+
+		assert.NoError(t, cache.Close())
+		assert.Error(t, cache.Ping(context.Background()))
+	})
 }
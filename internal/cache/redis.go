@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jonboulle/clockwork"
+
+	"news-app/internal/domain"
+	"news-app/internal/logging"
+)
+
+// redisKeyPrefix namespaces cache entries so the cache can share a redis database
+const redisKeyPrefix = "news-app:articles:"
+
+// redisCache is the internal representation of a cache shared between replicas through a server speaking the redis protocol
+type redisCache struct {
+	client redis.UniversalClient
+	ttl    time.Duration
+	grace  time.Duration
+	clock  clockwork.Clock
+}
+
+// redisEntry is the serialised form of a cache entry, created is stored so every replica agrees on whether an entry is stale
+type redisEntry struct {
+	Created  time.Time        `json:"created"`
+	Articles []domain.Article `json:"articles"`
+}
+
+// NewRedisCache is a constructor for a Cache backed by redis
+// ttlDuration represents how long a cache entry is fresh
+// graceDuration represents how long an entry is kept as stale once the ttl has passed, entries expire on the server after both
+// Eviction is left to the server's maxmemory policy
+func NewRedisCache(client redis.UniversalClient, ttlDuration, graceDuration time.Duration, clock clockwork.Clock) Cache {
+	return &redisCache{
+		client: client,
+		ttl:    ttlDuration,
+		grace:  graceDuration,
+		clock:  clock,
+	}
+}
+
+// GetArticlesFromCache will return the articles it found along with whether they are Fresh or Stale, and how much longer Fresh articles
+// stay fresh. It will return Missing on a miss and a nil slice.
+// An unavailable server is treated as a miss so feeds are still served by parsing them.
+func (c *redisCache) GetArticlesFromCache(url string) ([]domain.Article, Status, time.Duration) {
+	value, err := c.client.Get(context.Background(), redisKeyPrefix+url).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			logging.Default().Warn("failed to get from redis cache", "feed_url", url, "error", err)
+		}
+		return nil, Missing, 0
+	}
+
+	var entry redisEntry
+	if err := json.Unmarshal(value, &entry); err != nil {
+		logging.Default().Warn("failed to decode from redis cache", "feed_url", url, "error", err)
+		return nil, Missing, 0
+	}
+
+	age := c.clock.Since(entry.Created)
+	switch {
+	case age < c.ttl:
+		return entry.Articles, Fresh, c.ttl - age
+	case age < c.ttl+c.grace:
+		return entry.Articles, Stale, 0
+	default:
+		return nil, Missing, 0
+	}
+}
+
+// AddArticlesToCache will add or overwrite articles in the cache, the server expires them once the ttl and grace window have passed
+func (c *redisCache) AddArticlesToCache(url string, articles []domain.Article) {
+	value, err := json.Marshal(redisEntry{
+		Created:  c.clock.Now(),
+		Articles: articles,
+	})
+	if err != nil {
+		logging.Default().Warn("failed to encode for redis cache", "feed_url", url, "error", err)
+		return
+	}
+
+	if err := c.client.Set(context.Background(), redisKeyPrefix+url, value, c.ttl+c.grace).Err(); err != nil {
+		logging.Default().Warn("failed to add to redis cache", "feed_url", url, "error", err)
+	}
+}
+
+// DeleteArticlesFromCache removes a feed from the cache, it returns false if the feed was not cached or the server is unavailable
+func (c *redisCache) DeleteArticlesFromCache(url string) bool {
+	deleted, err := c.client.Del(context.Background(), redisKeyPrefix+url).Result()
+	if err != nil {
+		logging.Default().Warn("failed to delete from redis cache", "feed_url", url, "error", err)
+		return false
+	}
+
+	return deleted > 0
+}
+
+// Keys returns the url of every cached feed in no particular order
+func (c *redisCache) Keys() []string {
+	var keys []string
+
+	iter := c.client.Scan(context.Background(), 0, redisKeyPrefix+"*", 0).Iterator()
+	for iter.Next(context.Background()) {
+		keys = append(keys, strings.TrimPrefix(iter.Val(), redisKeyPrefix))
+	}
+	if err := iter.Err(); err != nil {
+		logging.Default().Warn("failed to list redis cache keys", "error", err)
+	}
+
+	return keys
+}
+
+// GetEntry returns metadata about a cached feed, the size is the size of the serialised entry. It returns false if the feed is not cached
+func (c *redisCache) GetEntry(url string) (domain.CacheEntry, bool) {
+	value, err := c.client.Get(context.Background(), redisKeyPrefix+url).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			logging.Default().Warn("failed to get from redis cache", "feed_url", url, "error", err)
+		}
+		return domain.CacheEntry{}, false
+	}
+
+	var entry redisEntry
+	if err := json.Unmarshal(value, &entry); err != nil {
+		logging.Default().Warn("failed to decode from redis cache", "feed_url", url, "error", err)
+		return domain.CacheEntry{}, false
+	}
+
+	return newCacheEntry(url, entry.Created, len(value), c.clock.Since(entry.Created), c.ttl), true
+}
+
+// Stats returns the number of entries in the cache. The server manages memory and eviction so Bytes and Evictions are not reported
+func (c *redisCache) Stats() Stats {
+	return Stats{
+		Entries: len(c.Keys()),
+	}
+}
+
+// Ping reports whether the redis server can be reached
+func (c *redisCache) Ping(ctx context.Context) error {
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the redis client the cache was created with
+func (c *redisCache) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+
+	"news-app/internal/domain"
+)
+
+func Test_redisCache(t *testing.T) {
+	const someURL = "some-url"
+	var (
+		someArticles = []domain.Article{
+			{
+				ID:        "some-id",
+				Title:     "some-title",
+				Published: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		}
+		someTTLDuration   = 5 * time.Second
+		someGraceDuration = 10 * time.Second
+	)
+
+	setup := func(t *testing.T) (Cache, *miniredis.Miniredis, clockwork.FakeClock) {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+
+		clock := clockwork.NewFakeClock()
+		return NewRedisCache(client, someTTLDuration, someGraceDuration, clock), server, clock
+	}
+
+	t.Run("should return cache hit and articles", func(t *testing.T) {
+		cache, _, _ := setup(t)
+
+		cache.AddArticlesToCache(someURL, someArticles)
+
+		articles, status, _ := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Fresh, status)
+		assert.Equal(t, someArticles, articles)
+		assert.Equal(t, Stats{Entries: 1}, cache.Stats())
+	})
+	t.Run("should return cache miss and nil articles", func(t *testing.T) {
+		cache, _, _ := setup(t)
+
+		articles, status, _ := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Missing, status)
+		assert.Nil(t, articles)
+	})
+	t.Run("should return stale articles once ttl has passed", func(t *testing.T) {
+		cache, _, clock := setup(t)
+
+		cache.AddArticlesToCache(someURL, someArticles)
+		clock.Advance(someTTLDuration / 5)
+
+		_, status, freshFor := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Fresh, status)
+		assert.Equal(t, 4*someTTLDuration/5, freshFor)
+
+		clock.Advance(4 * someTTLDuration / 5)
+		articles, status, freshFor := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Stale, status)
+		assert.Zero(t, freshFor)
+		assert.Equal(t, someArticles, articles)
+	})
+	t.Run("should expire entries on the server once ttl and grace window have passed", func(t *testing.T) {
+		cache, server, _ := setup(t)
+
+		cache.AddArticlesToCache(someURL, someArticles)
+		assert.Equal(t, someTTLDuration+someGraceDuration, server.TTL(redisKeyPrefix+someURL))
+
+		server.FastForward(someTTLDuration + someGraceDuration)
+
+		articles, status, _ := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Missing, status)
+		assert.Nil(t, articles)
+	})
+	t.Run("should share entries between caches using the same server", func(t *testing.T) {
+		cache, server, clock := setup(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		defer client.Close()
+		otherCache := NewRedisCache(client, someTTLDuration, someGraceDuration, clock)
+
+		cache.AddArticlesToCache(someURL, someArticles)
+
+		articles, status, _ := otherCache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Fresh, status)
+		assert.Equal(t, someArticles, articles)
+	})
+	t.Run("should return a miss if the server is unavailable", func(t *testing.T) {
+		cache, server, _ := setup(t)
+
+		cache.AddArticlesToCache(someURL, someArticles)
+		server.Close()
+
+		articles, status, _ := cache.GetArticlesFromCache(someURL)
+		assert.Equal(t, Missing, status)
+		assert.Nil(t, articles)
+	})
+	t.Run("should delete, list and describe entries", func(t *testing.T) {
+		cache, _, clock := setup(t)
+
+		cache.AddArticlesToCache(someURL, someArticles)
+		created := clock.Now()
+		clock.Advance(time.Second)
+
+		assert.Equal(t, []string{someURL}, cache.Keys())
+
+		entry, ok := cache.GetEntry(someURL)
+		assert.True(t, ok)
+		assert.Equal(t, someURL, entry.URL)
+		assert.True(t, created.Equal(entry.Created))
+		assert.Equal(t, int64(1), entry.AgeSeconds)
+		assert.NotZero(t, entry.Size)
+		assert.False(t, entry.Stale)
+
+		assert.True(t, cache.DeleteArticlesFromCache(someURL))
+		assert.False(t, cache.DeleteArticlesFromCache(someURL))
+
+		_, ok = cache.GetEntry(someURL)
+		assert.False(t, ok)
+		assert.Empty(t, cache.Keys())
+	})
+	t.Run("should report whether the server can be reached", func(t *testing.T) {
+		cache, server, _ := setup(t)
+		assert.NoError(t, cache.Ping(context.Background()))
+
+		server.Close()
+		assert.Error(t, cache.Ping(context.Background()))
+	})
+}
@@ -0,0 +1,420 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix is prepended to the environment variable for every setting, server.addr is read from NEWS_APP_SERVER_ADDR
+const envPrefix = "NEWS_APP_"
+
+// Config is the configuration of the server
+type Config struct {
+	Server  Server  `yaml:"server"`
+	Parser  Parser  `yaml:"parser"`
+	Cache   Cache   `yaml:"cache"`
+	Store   Store   `yaml:"store"`
+	Refresh Refresh `yaml:"refresh"`
+	Health  Health  `yaml:"health"`
+}
+
+// Server configures the http server
+type Server struct {
+	Addr            string        `yaml:"addr" validate:"required,hostname_port"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" validate:"gt=0"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" validate:"gt=0"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" validate:"gt=0"`
+	// AdminToken is the bearer token required by the admin routes, they are disabled while it is empty
+	AdminToken string `yaml:"admin_token"`
+	// CompressionMinSize is the smallest response in bytes that is compressed for clients that accept it
+	CompressionMinSize int `yaml:"compression_min_size" validate:"gte=0"`
+}
+
+// Parser configures how feeds are fetched
+type Parser struct {
+	// Timeout bounds each fetch of a feed
+	Timeout time.Duration `yaml:"timeout" validate:"gt=0"`
+	// AllowedSchemes, AllowedHosts and DeniedHosts decide which feed URLs may be fetched, AllowedHosts allows any host while it is empty
+	AllowedSchemes []string `yaml:"allowed_schemes" validate:"min=1,dive,oneof=http https"`
+	AllowedHosts   []string `yaml:"allowed_hosts" validate:"dive,hostname_rfc1123"`
+	DeniedHosts    []string `yaml:"denied_hosts" validate:"dive,hostname_rfc1123"`
+	// AllowPrivateNetworks allows feeds on loopback, private and link local addresses, it should only be set for local development
+	AllowPrivateNetworks bool   `yaml:"allow_private_networks"`
+	MaxRedirects         int    `yaml:"max_redirects" validate:"gte=0"`
+	UserAgent            string `yaml:"user_agent" validate:"required"`
+	// MaxBodyBytes is the largest feed that is downloaded
+	MaxBodyBytes int `yaml:"max_body_bytes" validate:"gt=0"`
+	// HostHeaders are extra headers sent to particular hosts, such as API keys, keyed by host name and then header name
+	HostHeaders map[string]map[string]string `yaml:"host_headers"`
+	// HostInterval, HostBurst and HostConcurrency limit how often and how many feeds are fetched from each host
+	HostInterval    time.Duration `yaml:"host_interval" validate:"gte=0"`
+	HostBurst       int           `yaml:"host_burst" validate:"min=1"`
+	HostConcurrency int           `yaml:"host_concurrency" validate:"min=1"`
+	// MinBackoff and MaxBackoff bound how long a failing host is left alone, the backoff doubles with each further failure in a row
+	MinBackoff time.Duration `yaml:"min_backoff" validate:"gt=0"`
+	MaxBackoff time.Duration `yaml:"max_backoff" validate:"gtefield=MinBackoff"`
+	// RetryAttempts is the most times a feed is fetched for one call, RetryMinDelay and RetryMaxDelay bound the wait between them
+	// and RetryTimeout bounds the whole call, every attempt and wait included
+	RetryAttempts int           `yaml:"retry_attempts" validate:"min=1"`
+	RetryMinDelay time.Duration `yaml:"retry_min_delay" validate:"gt=0"`
+	RetryMaxDelay time.Duration `yaml:"retry_max_delay" validate:"gtefield=RetryMinDelay"`
+	RetryTimeout  time.Duration `yaml:"retry_timeout" validate:"gtefield=Timeout"`
+	// BreakerThreshold is how many calls for a feed fail in a row before it is left alone for BreakerCoolDown
+	BreakerThreshold int           `yaml:"breaker_threshold" validate:"min=1"`
+	BreakerCoolDown  time.Duration `yaml:"breaker_cool_down" validate:"gt=0"`
+}
+
+// Cache configures where articles are cached and for how long
+type Cache struct {
+	// Backend is either memory to cache in process or redis to share the cache between replicas
+	Backend         string        `yaml:"backend" validate:"oneof=memory redis"`
+	TTL             time.Duration `yaml:"ttl" validate:"gt=0"`
+	StaleGrace      time.Duration `yaml:"stale_grace" validate:"gte=0"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval" validate:"gt=0"`
+	// MaxEntries and MaxBytes limit the memory backend, 0 means unlimited
+	MaxEntries int    `yaml:"max_entries" validate:"gte=0"`
+	MaxBytes   int    `yaml:"max_bytes" validate:"gte=0"`
+	RedisAddr  string `yaml:"redis_addr" validate:"required_if=Backend redis,omitempty,hostname_port"`
+}
+
+// Store configures where feeds and articles are persisted
+type Store struct {
+	FeedsPath    string `yaml:"feeds_path" validate:"required"`
+	ArticlesPath string `yaml:"articles_path" validate:"required"`
+}
+
+// Refresh configures the background poller
+type Refresh struct {
+	Interval time.Duration `yaml:"interval" validate:"gt=0"`
+	Jitter   time.Duration `yaml:"jitter" validate:"gte=0"`
+	Workers  int           `yaml:"workers" validate:"min=1"`
+	Ticker   time.Duration `yaml:"ticker" validate:"gt=0"`
+}
+
+// Health configures the readiness checks
+type Health struct {
+	CheckTimeout time.Duration `yaml:"check_timeout" validate:"gt=0"`
+	// CriticalFeeds must have been parsed within MaxFeedAge for the server to be ready
+	CriticalFeeds []string      `yaml:"critical_feeds" validate:"dive,url"`
+	MaxFeedAge    time.Duration `yaml:"max_feed_age" validate:"gt=0"`
+}
+
+// Options are command line options that control loading rather than being part of the configuration
+type Options struct {
+	// Path is the YAML or JSON file the configuration was loaded from, if any
+	Path string
+	// PrintConfig asks for the effective configuration to be printed instead of starting the server
+	PrintConfig bool
+}
+
+// Default returns the configuration used for any setting that is not given
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:               "127.0.0.1:8080",
+			ReadTimeout:        10 * time.Second,
+			WriteTimeout:       10 * time.Second,
+			ShutdownTimeout:    30 * time.Second,
+			CompressionMinSize: 1024,
+		},
+		Parser: Parser{
+			Timeout:          10 * time.Second,
+			AllowedSchemes:   []string{"http", "https"},
+			AllowedHosts:     []string{},
+			DeniedHosts:      []string{},
+			MaxRedirects:     5,
+			UserAgent:        "news-app/1.0",
+			MaxBodyBytes:     10 << 20,
+			HostHeaders:      map[string]map[string]string{},
+			HostInterval:     time.Second,
+			HostBurst:        5,
+			HostConcurrency:  2,
+			MinBackoff:       5 * time.Second,
+			MaxBackoff:       10 * time.Minute,
+			RetryAttempts:    3,
+			RetryMinDelay:    200 * time.Millisecond,
+			RetryMaxDelay:    2 * time.Second,
+			RetryTimeout:     30 * time.Second,
+			BreakerThreshold: 5,
+			BreakerCoolDown:  time.Minute,
+		},
+		Cache: Cache{
+			Backend:         "memory",
+			TTL:             5 * time.Minute,
+			StaleGrace:      1 * time.Hour,
+			CleanupInterval: 1 * time.Minute,
+			MaxEntries:      1000,
+			MaxBytes:        256 << 20,
+			RedisAddr:       "localhost:6379",
+		},
+		Store: Store{
+			FeedsPath:    "feeds.json",
+			ArticlesPath: "articles.db",
+		},
+		Refresh: Refresh{
+			Interval: 4 * time.Minute,
+			Jitter:   30 * time.Second,
+			Workers:  4,
+			Ticker:   10 * time.Second,
+		},
+		Health: Health{
+			CheckTimeout:  2 * time.Second,
+			CriticalFeeds: []string{},
+			MaxFeedAge:    15 * time.Minute,
+		},
+	}
+}
+
+// Load builds the configuration from defaults, then the file given by --config, then environment variables and finally command line flags,
+// each overriding the last. JSON files are read as YAML which they are a subset of.
+func Load(args []string, lookupEnv func(string) (string, bool)) (Config, Options, error) {
+	cfg := Default()
+	var opts Options
+
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.StringVar(&opts.Path, "config", "", "YAML or JSON configuration file")
+	fs.BoolVar(&opts.PrintConfig, "print-config", false, "print the effective configuration and exit")
+	settings := bindSettings(fs, &cfg)
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, Options{}, err
+	}
+
+	// parsing wrote the flags into cfg, remember them so they can be applied again once the file and environment have been read
+	flags := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		if settings[f.Name] {
+			flags[f.Name] = f.Value.String()
+		}
+	})
+	cfg = Default()
+
+	if opts.Path != "" {
+		if err := loadFile(opts.Path, &cfg); err != nil {
+			return Config{}, Options{}, err
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if !settings[f.Name] || err != nil {
+			return
+		}
+
+		name := envName(f.Name)
+		if value, ok := lookupEnv(name); ok {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid value %q for %s: %w", value, name, setErr)
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, Options{}, err
+	}
+
+	for name, value := range flags {
+		if err := fs.Set(name, value); err != nil {
+			return Config{}, Options{}, err
+		}
+	}
+
+	if err := validator.New().Struct(cfg); err != nil {
+		return Config{}, Options{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, opts, nil
+}
+
+// bindSettings registers a flag for every setting which writes to cfg, it returns the names of the flags it registered
+func bindSettings(fs *flag.FlagSet, cfg *Config) map[string]bool {
+	settings := make(map[string]bool)
+	str := func(p *string, name, usage string) {
+		fs.StringVar(p, name, *p, usage)
+		settings[name] = true
+	}
+	duration := func(p *time.Duration, name, usage string) {
+		fs.DurationVar(p, name, *p, usage)
+		settings[name] = true
+	}
+	integer := func(p *int, name, usage string) {
+		fs.IntVar(p, name, *p, usage)
+		settings[name] = true
+	}
+	boolean := func(p *bool, name, usage string) {
+		fs.BoolVar(p, name, *p, usage)
+		settings[name] = true
+	}
+	list := func(p *[]string, name, usage string) {
+		fs.Var((*stringList)(p), name, usage)
+		settings[name] = true
+	}
+	headers := func(p *map[string]map[string]string, name, usage string) {
+		fs.Var((*hostHeaders)(p), name, usage)
+		settings[name] = true
+	}
+
+	str(&cfg.Server.Addr, "server.addr", "address the server listens on")
+	duration(&cfg.Server.ReadTimeout, "server.read_timeout", "maximum time to read a request")
+	duration(&cfg.Server.WriteTimeout, "server.write_timeout", "maximum time to write a response")
+	duration(&cfg.Server.ShutdownTimeout, "server.shutdown_timeout", "how long in-flight requests are given to complete on shutdown")
+	str(&cfg.Server.AdminToken, "server.admin_token", "bearer token for the admin routes, admin routes are disabled when empty")
+	integer(&cfg.Server.CompressionMinSize, "server.compression_min_size", "smallest response in bytes compressed with gzip or brotli")
+
+	duration(&cfg.Parser.Timeout, "parser.timeout", "timeout on each call to an rss feed")
+	list(&cfg.Parser.AllowedSchemes, "parser.allowed_schemes", "comma separated URL schemes feeds may use")
+	list(&cfg.Parser.AllowedHosts, "parser.allowed_hosts", "comma separated hosts feeds are limited to along with their subdomains, any host when empty")
+	list(&cfg.Parser.DeniedHosts, "parser.denied_hosts", "comma separated hosts feeds may not use along with their subdomains")
+	boolean(&cfg.Parser.AllowPrivateNetworks, "parser.allow_private_networks", "allow feeds on loopback, private and link local addresses, for local development only")
+	integer(&cfg.Parser.MaxRedirects, "parser.max_redirects", "maximum number of redirects followed when fetching a feed")
+	str(&cfg.Parser.UserAgent, "parser.user_agent", "User-Agent sent when fetching feeds")
+	integer(&cfg.Parser.MaxBodyBytes, "parser.max_body_bytes", "largest feed in bytes that is downloaded")
+	headers(&cfg.Parser.HostHeaders, "parser.host_headers", "comma separated host=Header:value pairs sent when fetching feeds from that host")
+	duration(&cfg.Parser.HostInterval, "parser.host_interval", "time between fetches from the same host once its burst is used")
+	integer(&cfg.Parser.HostBurst, "parser.host_burst", "fetches that can be made at once from a host that has been idle")
+	integer(&cfg.Parser.HostConcurrency, "parser.host_concurrency", "maximum fetches from the same host in progress at once")
+	duration(&cfg.Parser.MinBackoff, "parser.min_backoff", "how long a host is left alone after two failed fetches in a row, doubled for each further failure")
+	duration(&cfg.Parser.MaxBackoff, "parser.max_backoff", "longest a failing host is left alone unless it sends a longer Retry-After")
+	integer(&cfg.Parser.RetryAttempts, "parser.retry_attempts", "most times a feed is fetched for one request when it fails for a reason that may pass")
+	duration(&cfg.Parser.RetryMinDelay, "parser.retry_min_delay", "wait before fetching a feed again, doubled for each attempt")
+	duration(&cfg.Parser.RetryMaxDelay, "parser.retry_max_delay", "longest wait before fetching a feed again, retries that would wait longer are not made")
+	duration(&cfg.Parser.RetryTimeout, "parser.retry_timeout", "timeout on fetching a feed for one request, every attempt and the waits between them included")
+	integer(&cfg.Parser.BreakerThreshold, "parser.breaker_threshold", "failures in a row after which a feed is not fetched for the cool down")
+	duration(&cfg.Parser.BreakerCoolDown, "parser.breaker_cool_down", "how long a feed that keeps failing is not fetched for")
+
+	str(&cfg.Cache.Backend, "cache.backend", "where articles are cached, memory or redis")
+	duration(&cfg.Cache.TTL, "cache.ttl", "how long cached articles are fresh")
+	duration(&cfg.Cache.StaleGrace, "cache.stale_grace", "how long expired articles are still served while the feed is refreshed or its publisher is down")
+	duration(&cfg.Cache.CleanupInterval, "cache.cleanup_interval", "time between each cache evaluation")
+	integer(&cfg.Cache.MaxEntries, "cache.max_entries", "maximum number of feeds held in the memory cache, 0 for unlimited")
+	integer(&cfg.Cache.MaxBytes, "cache.max_bytes", "approximate maximum size of the articles held in the memory cache, 0 for unlimited")
+	str(&cfg.Cache.RedisAddr, "cache.redis_addr", "address of the redis server used by the redis cache")
+
+	str(&cfg.Store.FeedsPath, "store.feeds_path", "file registered feeds are persisted to")
+	str(&cfg.Store.ArticlesPath, "store.articles_path", "database every parsed article is persisted to")
+
+	duration(&cfg.Refresh.Interval, "refresh.interval", "how often registered feeds are refreshed unless they set their own interval")
+	duration(&cfg.Refresh.Jitter, "refresh.jitter", "maximum random delay added to each background refresh")
+	integer(&cfg.Refresh.Workers, "refresh.workers", "maximum number of feeds refreshed at once")
+	duration(&cfg.Refresh.Ticker, "refresh.ticker", "time between each check for feeds that are due a refresh")
+
+	duration(&cfg.Health.CheckTimeout, "health.check_timeout", "how long each readiness check is given before it fails")
+	list(&cfg.Health.CriticalFeeds, "health.critical_feeds", "comma separated feed URLs that must have been parsed recently for the server to be ready")
+	duration(&cfg.Health.MaxFeedAge, "health.max_feed_age", "how recently critical feeds must have been parsed")
+
+	return settings
+}
+
+// stringList is a flag holding a comma separated list, setting it replaces the list rather than appending so flags can be applied
+// over the file and environment
+type stringList []string
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// hostHeaders is a flag holding headers per host as comma separated host=Header:value pairs, setting it replaces every header
+type hostHeaders map[string]map[string]string
+
+func (h *hostHeaders) String() string {
+	if h == nil {
+		return ""
+	}
+
+	var pairs []string
+	for host, headers := range *h {
+		for name, value := range headers {
+			pairs = append(pairs, host+"="+name+":"+value)
+		}
+	}
+	// maps are unordered, sort so the same headers always print the same
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+func (h *hostHeaders) Set(value string) error {
+	*h = make(map[string]map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		host, header, ok := strings.Cut(pair, "=")
+		name, headerValue, ok2 := strings.Cut(header, ":")
+		if !ok || !ok2 || host == "" || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%q is not a host=Header:value pair", pair)
+		}
+
+		if (*h)[host] == nil {
+			(*h)[host] = make(map[string]string)
+		}
+		(*h)[host][strings.TrimSpace(name)] = strings.TrimSpace(headerValue)
+	}
+	return nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// envName turns a flag name such as cache.max_entries into its environment variable NEWS_APP_CACHE_MAX_ENTRIES
+func envName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, ".", "_"))
+}
+
+// Print writes the configuration as YAML, the admin token and the values of host headers, which often hold API keys, are redacted
+func Print(w io.Writer, cfg Config) error {
+	if cfg.Server.AdminToken != "" {
+		cfg.Server.AdminToken = "REDACTED"
+	}
+
+	// copy the headers rather than redacting them in place as the map is shared with the caller's configuration
+	hostHeaders := make(map[string]map[string]string, len(cfg.Parser.HostHeaders))
+	for host, headers := range cfg.Parser.HostHeaders {
+		hostHeaders[host] = make(map[string]string, len(headers))
+		for name := range headers {
+			hostHeaders[host][name] = "REDACTED"
+		}
+	}
+	cfg.Parser.HostHeaders = hostHeaders
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	defer encoder.Close()
+
+	return encoder.Encode(cfg)
+}
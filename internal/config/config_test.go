@@ -0,0 +1,174 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_Load(t *testing.T) {
+	env := func(values map[string]string) func(string) (string, bool) {
+		return func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		}
+	}
+
+	writeFile := func(t *testing.T, name, content string) string {
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	t.Run("should return the defaults when nothing is given", func(t *testing.T) {
+		cfg, opts, err := Load(nil, env(nil))
+		require.NoError(t, err)
+
+		assert.Equal(t, Default(), cfg)
+		assert.Equal(t, Options{}, opts)
+	})
+	t.Run("should read a yaml file", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "server:\n  addr: 0.0.0.0:9090\ncache:\n  ttl: 1m\n")
+
+		cfg, opts, err := Load([]string{"--config", path}, env(nil))
+		require.NoError(t, err)
+
+		assert.Equal(t, "0.0.0.0:9090", cfg.Server.Addr)
+		assert.Equal(t, time.Minute, cfg.Cache.TTL)
+		assert.Equal(t, Default().Cache.StaleGrace, cfg.Cache.StaleGrace)
+		assert.Equal(t, path, opts.Path)
+	})
+	t.Run("should read a json file", func(t *testing.T) {
+		path := writeFile(t, "config.json", `{"refresh": {"workers": 8, "interval": "10m"}}`)
+
+		cfg, _, err := Load([]string{"--config", path}, env(nil))
+		require.NoError(t, err)
+
+		assert.Equal(t, 8, cfg.Refresh.Workers)
+		assert.Equal(t, 10*time.Minute, cfg.Refresh.Interval)
+	})
+	t.Run("should override the file with the environment and the environment with flags", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "server:\n  addr: 0.0.0.0:9090\ncache:\n  ttl: 1m\n  max_entries: 10\n")
+
+		cfg, _, err := Load([]string{"--config", path, "--cache.ttl", "3m"}, env(map[string]string{
+			"NEWS_APP_CACHE_TTL":         "2m",
+			"NEWS_APP_CACHE_MAX_ENTRIES": "20",
+		}))
+		require.NoError(t, err)
+
+		assert.Equal(t, "0.0.0.0:9090", cfg.Server.Addr)
+		assert.Equal(t, 3*time.Minute, cfg.Cache.TTL)
+		assert.Equal(t, 20, cfg.Cache.MaxEntries)
+	})
+	t.Run("should replace lists from the file with comma separated lists from the environment and flags", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "health:\n  critical_feeds:\n    - https://some-host.com/rss\n")
+
+		cfg, _, err := Load([]string{"--config", path}, env(map[string]string{
+			"NEWS_APP_HEALTH_CRITICAL_FEEDS": "https://other-host.com/rss, https://another-host.com/rss",
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://other-host.com/rss", "https://another-host.com/rss"}, cfg.Health.CriticalFeeds)
+
+		cfg, _, err = Load([]string{"--config", path, "--health.critical_feeds", "https://flag-host.com/rss"}, env(map[string]string{
+			"NEWS_APP_HEALTH_CRITICAL_FEEDS": "https://other-host.com/rss",
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://flag-host.com/rss"}, cfg.Health.CriticalFeeds)
+
+		_, _, err = Load([]string{"--health.critical_feeds", "not a url"}, env(nil))
+		assert.Error(t, err)
+	})
+	t.Run("should read the feed url policy", func(t *testing.T) {
+		cfg, _, err := Load([]string{"--parser.allowed_schemes", "https", "--parser.denied_hosts", "some-host.com"}, env(map[string]string{
+			"NEWS_APP_PARSER_ALLOW_PRIVATE_NETWORKS": "true",
+			"NEWS_APP_PARSER_MAX_REDIRECTS":          "2",
+		}))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"https"}, cfg.Parser.AllowedSchemes)
+		assert.Equal(t, []string{"some-host.com"}, cfg.Parser.DeniedHosts)
+		assert.True(t, cfg.Parser.AllowPrivateNetworks)
+		assert.Equal(t, 2, cfg.Parser.MaxRedirects)
+
+		_, _, err = Load([]string{"--parser.allowed_schemes", "file"}, env(nil))
+		assert.Error(t, err)
+	})
+	t.Run("should read headers per host from the file and flags", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "parser:\n  host_headers:\n    some-host.com:\n      X-Api-Key: some-key\n")
+
+		cfg, _, err := Load([]string{"--config", path}, env(nil))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]map[string]string{"some-host.com": {"X-Api-Key": "some-key"}}, cfg.Parser.HostHeaders)
+
+		cfg, _, err = Load([]string{"--config", path, "--parser.host_headers", "other-host.com=Authorization:Bearer some-token, other-host.com=X-Api-Key:other-key"}, env(nil))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]map[string]string{
+			"other-host.com": {"Authorization": "Bearer some-token", "X-Api-Key": "other-key"},
+		}, cfg.Parser.HostHeaders)
+
+		_, _, err = Load([]string{"--parser.host_headers", "some-host.com"}, env(nil))
+		assert.Error(t, err)
+	})
+	t.Run("should return the print config option", func(t *testing.T) {
+		_, opts, err := Load([]string{"--print-config"}, env(nil))
+		require.NoError(t, err)
+
+		assert.True(t, opts.PrintConfig)
+	})
+	t.Run("should return an error for unknown settings in the file", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "cache:\n  tll: 1m\n")
+
+		_, _, err := Load([]string{"--config", path}, env(nil))
+		assert.Error(t, err)
+	})
+	t.Run("should return an error for an invalid environment variable", func(t *testing.T) {
+		_, _, err := Load(nil, env(map[string]string{"NEWS_APP_REFRESH_WORKERS": "many"}))
+		assert.ErrorContains(t, err, "NEWS_APP_REFRESH_WORKERS")
+	})
+	t.Run("should return an error if the configuration is invalid", func(t *testing.T) {
+		_, _, err := Load([]string{"--cache.backend", "memcached"}, env(nil))
+		assert.Error(t, err)
+
+		_, _, err = Load([]string{"--refresh.workers", "0"}, env(nil))
+		assert.Error(t, err)
+
+		_, _, err = Load([]string{"--cache.backend", "redis", "--cache.redis_addr", ""}, env(nil))
+		assert.Error(t, err)
+
+		_, _, err = Load([]string{"--parser.min_backoff", "1m", "--parser.max_backoff", "30s"}, env(nil))
+		assert.Error(t, err)
+
+		_, _, err = Load([]string{"--parser.retry_attempts", "0"}, env(nil))
+		assert.Error(t, err)
+
+		_, _, err = Load([]string{"--parser.timeout", "10s", "--parser.retry_timeout", "5s"}, env(nil))
+		assert.Error(t, err)
+	})
+}
+
+func Test_Print(t *testing.T) {
+	t.Run("should print the configuration as yaml with the admin token and host headers redacted", func(t *testing.T) {
+		cfg := Default()
+		cfg.Server.AdminToken = "some-token"
+		cfg.Parser.HostHeaders = map[string]map[string]string{"some-host.com": {"X-Api-Key": "some-key"}}
+
+		var buf bytes.Buffer
+		require.NoError(t, Print(&buf, cfg))
+
+		assert.NotContains(t, buf.String(), "some-token")
+		assert.NotContains(t, buf.String(), "some-key")
+		assert.Equal(t, "some-key", cfg.Parser.HostHeaders["some-host.com"]["X-Api-Key"])
+
+		var printed Config
+		require.NoError(t, yaml.Unmarshal(buf.Bytes(), &printed))
+		cfg.Server.AdminToken = "REDACTED"
+		cfg.Parser.HostHeaders = map[string]map[string]string{"some-host.com": {"X-Api-Key": "REDACTED"}}
+		assert.Equal(t, cfg, printed)
+	})
+}
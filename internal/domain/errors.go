@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrFeedNotFound is returned when a subscription does not exist
+	ErrFeedNotFound = errors.New("feed not found")
+	// ErrFeedAlreadyExists is returned when a subscription for the same URL has already been registered
+	ErrFeedAlreadyExists = errors.New("feed already exists")
+	// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrNotCached is returned when a feed has no cache entry
+	ErrNotCached = errors.New("feed not cached")
+	// ErrInvalidQuery is returned when a search query contains no searchable terms
+	ErrInvalidQuery = errors.New("invalid query")
+)
+
+// Feed fetch failures, they are the Kind of a FetchError
+var (
+	// ErrInvalidFeedURL is returned when a feed URL cannot be requested at all
+	ErrInvalidFeedURL = errors.New("invalid feed url")
+	// ErrBlockedURL is returned when a feed URL points somewhere feeds may not be fetched from
+	ErrBlockedURL = errors.New("feed url blocked")
+	// ErrFeedUnreachable is returned when the publisher cannot be connected to
+	ErrFeedUnreachable = errors.New("feed unreachable")
+	// ErrFeedTimeout is returned when the publisher does not respond in time
+	ErrFeedTimeout = errors.New("feed timed out")
+	// ErrHostThrottled is returned when we hold back from fetching a feed because its host is failing, asked us to slow down
+	// or is already being fetched from as much as we allow
+	ErrHostThrottled = errors.New("feed host throttled")
+	// ErrCircuitOpen is returned when we hold back from fetching a feed because it has failed too many times in a row
+	ErrCircuitOpen = errors.New("feed circuit open")
+	// ErrUpstreamStatus is returned when the publisher responds with an error status
+	ErrUpstreamStatus = errors.New("feed returned an error status")
+	// ErrFeedTooLarge is returned when the publisher responds with a feed larger than we are willing to download
+	ErrFeedTooLarge = errors.New("feed too large")
+	// ErrMalformedFeed is returned when the publisher responds with a feed that cannot be parsed
+	ErrMalformedFeed = errors.New("malformed feed")
+	// ErrUnsupportedFormat is returned when the publisher responds with something that is not RSS, Atom or JSON Feed
+	ErrUnsupportedFormat = errors.New("unsupported feed format")
+)
+
+// FetchError describes why a feed could not be fetched or parsed.
+// errors.Is matches its Kind and the error that caused it so both the category and the detail can be checked.
+type FetchError struct {
+	// Kind is one of the feed fetch failures such as ErrFeedTimeout
+	Kind error
+	// StatusCode is the status the publisher responded with for ErrUpstreamStatus
+	StatusCode int
+	// RetryAfter is how long to wait before fetching from the host again, from the publisher's Retry-After for ErrUpstreamStatus
+	// or from our own backoff for ErrHostThrottled and ErrCircuitOpen. It is zero when there is no advice.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *FetchError) Error() string {
+	if e.Err == nil {
+		return e.Kind.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+func (e *FetchError) Is(target error) bool {
+	return target == e.Kind
+}
@@ -7,16 +7,27 @@ type Feed struct {
 	Title       string    `json:"title,omitempty"`
 	Description string    `json:"description,omitempty"`
 	Articles    []Article `json:"articles"`
+	// MovedTo is set when the publisher permanently redirected the feed, subscriptions to it should be moved to the new URL
+	MovedTo string `json:"-"`
 }
 
 // Article is our domain representation of an article
 type Article struct {
+	ID          string    `json:"id,omitempty"`
 	Title       string    `json:"title,omitempty"`
 	Description string    `json:"description,omitempty"`
 	Content     string    `json:"content,omitempty"`
 	Image       Image     `json:"image,omitempty"`
 	URL         string    `json:"url,omitempty"`
 	Published   time.Time `json:"published,omitempty"`
+	Source      *Source   `json:"source,omitempty"`
+}
+
+// Source is our domain representation of the feed an article was collected from
+type Source struct {
+	FeedID string `json:"feed_id,omitempty"`
+	URL    string `json:"url"`
+	Title  string `json:"title,omitempty"`
 }
 
 // Image is our domain representation of an image
@@ -24,3 +35,96 @@ type Image struct {
 	URL   string `json:"url,omitempty"`
 	Title string `json:"title,omitempty"`
 }
+
+// Subscription is our domain representation of a feed that has been registered with the app
+type Subscription struct {
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	Title    string `json:"title,omitempty"`
+	Category string `json:"category,omitempty"`
+	// RefreshIntervalSeconds overrides how often the feed is refreshed in the background, zero uses the default
+	RefreshIntervalSeconds int `json:"refresh_interval_seconds,omitempty"`
+}
+
+// SubscriptionUpdate holds the fields of a Subscription that should be changed, nil fields are left untouched
+type SubscriptionUpdate struct {
+	URL      *string
+	Title    *string
+	Category *string
+
+	RefreshIntervalSeconds *int
+}
+
+// ArticlePage is our domain representation of a single page of articles
+type ArticlePage struct {
+	Articles []Article `json:"articles"`
+	// NextCursor is passed back in a PageRequest to fetch the following page, it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Stale is set when articles were served from an expired cache entry while the feed is refreshed, it is reported in headers
+	Stale bool `json:"-"`
+	// MaxAge is how much longer the articles stay fresh in the cache, clients may reuse the response for as long
+	MaxAge time.Duration `json:"-"`
+}
+
+// PageRequest describes which page of articles to return
+type PageRequest struct {
+	// Limit is the maximum number of articles returned, zero returns every article
+	Limit int
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor string
+}
+
+// Timeline is our domain representation of articles merged from several feeds
+type Timeline struct {
+	ArticlePage
+	Errors []FeedError `json:"errors,omitempty"`
+}
+
+// FeedError describes why a single feed could not be included in a Timeline
+type FeedError struct {
+	Source string `json:"source"`
+	Error  string `json:"error"`
+}
+
+// TimelineOptions controls how a Timeline is built
+type TimelineOptions struct {
+	Page PageRequest
+}
+
+// SearchQuery is our domain representation of a full text search over collected articles
+type SearchQuery struct {
+	// Query is a list of terms that must all match, terms in double quotes must appear together as a phrase
+	Query string
+	// Feeds restricts results to these feed URLs, empty searches every feed
+	Feeds []string
+	// From and To restrict results to articles published within the range, zero values are unbounded
+	From time.Time
+	To   time.Time
+	Page PageRequest
+}
+
+// SearchResults is our domain representation of a page of articles ordered by relevance
+type SearchResults struct {
+	Results []SearchResult `json:"results"`
+	// Total is the number of articles that matched across every page
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// SearchResult is an article that matched a SearchQuery along with its relevance score
+type SearchResult struct {
+	Article
+	Score float64 `json:"score"`
+}
+
+// CacheEntry describes a feed held in the cache
+type CacheEntry struct {
+	URL        string    `json:"url"`
+	Created    time.Time `json:"created"`
+	AgeSeconds int64     `json:"age_seconds"`
+	// ExpiresInSeconds is how long until the entry is stale, 0 once it is
+	ExpiresInSeconds int64 `json:"expires_in_seconds"`
+	// Size is the approximate size of the cached articles in bytes
+	Size  int  `json:"size"`
+	Stale bool `json:"stale"`
+}
@@ -0,0 +1,158 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"news-app/internal/cache"
+)
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+// Check reports whether a component is working, the error describes why it is not
+type Check func(ctx context.Context) error
+
+// Report is the readiness of the server and a breakdown of each component it depends on
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentReport `json:"components,omitempty"`
+}
+
+// ComponentReport is the result of a single check
+type ComponentReport struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Checker runs every registered check to decide whether the server is ready to serve traffic
+type Checker struct {
+	timeout time.Duration
+
+	mutex  sync.RWMutex
+	checks map[string]Check
+}
+
+// NewChecker is a constructor for a Checker, each check fails if it takes longer than timeout
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+		checks:  make(map[string]Check),
+	}
+}
+
+// Add registers a check under the name it is reported as, a check with the same name is replaced
+func (c *Checker) Add(name string, check Check) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.checks[name] = check
+}
+
+// Check runs every check concurrently, the server is only ready if all of them pass
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mutex.RLock()
+	names := make([]string, 0, len(c.checks))
+	for name := range c.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = c.checks[name]
+	}
+	c.mutex.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			errs[i] = run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:     statusOK,
+		Components: make(map[string]ComponentReport, len(names)),
+	}
+	for i, name := range names {
+		if errs[i] != nil {
+			report.Status = statusUnavailable
+			report.Components[name] = ComponentReport{Status: statusUnavailable, Error: errs[i].Error()}
+			continue
+		}
+		report.Components[name] = ComponentReport{Status: statusOK}
+	}
+
+	return report
+}
+
+// run calls check and gives up once ctx is done so a hanging component cannot hold up the report
+func run(ctx context.Context, check Check) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- check(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("check did not complete: %w", ctx.Err())
+	}
+}
+
+// ServeHTTP serves readiness, it responds 503 Service Unavailable with the same breakdown when any check fails
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+
+	status := http.StatusOK
+	if report.Status != statusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeReport(w, status, report)
+}
+
+// Live serves liveness, it only shows the process is able to handle requests so it never checks dependencies
+func Live(w http.ResponseWriter, _ *http.Request) {
+	writeReport(w, http.StatusOK, Report{Status: statusOK})
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	body, _ := json.Marshal(report)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// FeedFreshness checks a feed was parsed successfully within maxAge by the age of its cache entry.
+// The feed should be registered so the poller keeps refreshing it.
+func FeedFreshness(c cache.Cache, feedURL string, maxAge time.Duration) Check {
+	return func(context.Context) error {
+		entry, ok := c.GetEntry(feedURL)
+		if !ok {
+			return errors.New("feed has not been parsed")
+		}
+
+		if age := time.Duration(entry.AgeSeconds) * time.Second; age > maxAge {
+			return fmt.Errorf("feed was last parsed %s ago", age)
+		}
+
+		return nil
+	}
+}
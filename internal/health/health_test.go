@@ -0,0 +1,112 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"news-app/internal/cache"
+	"news-app/internal/domain"
+)
+
+func Test_Checker(t *testing.T) {
+	passing := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("some error") }
+
+	serve := func(t *testing.T, handler http.Handler) (int, Report) {
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var report Report
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &report))
+		return res.Code, report
+	}
+
+	t.Run("should be ready when every check passes", func(t *testing.T) {
+		checker := NewChecker(time.Second)
+		checker.Add("cache", passing)
+		checker.Add("scheduler", passing)
+
+		status, report := serve(t, checker)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, Report{
+			Status: "ok",
+			Components: map[string]ComponentReport{
+				"cache":     {Status: "ok"},
+				"scheduler": {Status: "ok"},
+			},
+		}, report)
+	})
+
+	t.Run("should be unavailable with the reason when any check fails", func(t *testing.T) {
+		checker := NewChecker(time.Second)
+		checker.Add("cache", passing)
+		checker.Add("scheduler", failing)
+
+		status, report := serve(t, checker)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, Report{
+			Status: "unavailable",
+			Components: map[string]ComponentReport{
+				"cache":     {Status: "ok"},
+				"scheduler": {Status: "unavailable", Error: "some error"},
+			},
+		}, report)
+	})
+
+	t.Run("should fail checks that do not complete within the timeout", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+
+		checker := NewChecker(10 * time.Millisecond)
+		checker.Add("store", func(context.Context) error {
+			<-block
+			return nil
+		})
+
+		report := checker.Check(context.Background())
+		assert.Equal(t, "unavailable", report.Status)
+		assert.Contains(t, report.Components["store"].Error, context.DeadlineExceeded.Error())
+	})
+}
+
+func Test_Live(t *testing.T) {
+	t.Run("should always be ok", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		Live(res, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"status":"ok"}`, res.Body.String())
+	})
+}
+
+func Test_FeedFreshness(t *testing.T) {
+	const someFeedURL = "https://some-host.com/rss"
+
+	t.Run("should pass when the feed was parsed within the max age", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockCache := cache.NewMockCache(ctrl)
+		mockCache.EXPECT().GetEntry(someFeedURL).Return(domain.CacheEntry{URL: someFeedURL, AgeSeconds: 60}, true)
+
+		assert.NoError(t, FeedFreshness(mockCache, someFeedURL, 5*time.Minute)(context.Background()))
+	})
+
+	t.Run("should fail when the feed was parsed too long ago or has not been parsed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockCache := cache.NewMockCache(ctrl)
+		mockCache.EXPECT().GetEntry(someFeedURL).Return(domain.CacheEntry{URL: someFeedURL, AgeSeconds: 600}, true)
+		mockCache.EXPECT().GetEntry(someFeedURL).Return(domain.CacheEntry{}, false)
+
+		check := FeedFreshness(mockCache, someFeedURL, 5*time.Minute)
+		assert.EqualError(t, check(context.Background()), "feed was last parsed 10m0s ago")
+		assert.EqualError(t, check(context.Background()), "feed has not been parsed")
+	})
+}
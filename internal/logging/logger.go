@@ -0,0 +1,140 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Logger writes structured log lines as JSON objects, one per line
+type Logger struct {
+	mutex *sync.Mutex
+	out   io.Writer
+	now   func() time.Time
+	// fields are key value pairs added to every line, keys are always strings
+	fields []interface{}
+}
+
+// New is a constructor for a Logger writing to w
+func New(w io.Writer) *Logger {
+	return &Logger{
+		mutex: &sync.Mutex{},
+		out:   w,
+		now:   time.Now,
+	}
+}
+
+// With returns a logger that adds the given key value pairs to every line
+func (l *Logger) With(keysAndValues ...interface{}) *Logger {
+	child := *l
+	child.fields = append(append([]interface{}(nil), l.fields...), keysAndValues...)
+
+	return &child
+}
+
+// Info logs a message about normal operation
+func (l *Logger) Info(msg string, keysAndValues ...interface{}) {
+	l.log("info", msg, keysAndValues)
+}
+
+// Warn logs a message about a failure the server recovered from
+func (l *Logger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log("warn", msg, keysAndValues)
+}
+
+// Error logs a message about a failure that affected a response or background task
+func (l *Logger) Error(msg string, keysAndValues ...interface{}) {
+	l.log("error", msg, keysAndValues)
+}
+
+func (l *Logger) log(level, msg string, keysAndValues []interface{}) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	writeField(&b, "time", l.now().UTC().Format(time.RFC3339Nano))
+	b.WriteByte(',')
+	writeField(&b, "level", level)
+	b.WriteByte(',')
+	writeField(&b, "msg", msg)
+
+	for _, kv := range [][]interface{}{l.fields, keysAndValues} {
+		for i := 0; i < len(kv); i += 2 {
+			key := fmt.Sprint(kv[i])
+			var value interface{} = "MISSING"
+			if i+1 < len(kv) {
+				value = kv[i+1]
+			}
+
+			b.WriteByte(',')
+			writeField(&b, key, value)
+		}
+	}
+	b.WriteString("}\n")
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_, _ = l.out.Write(b.Bytes())
+}
+
+func writeField(b *bytes.Buffer, key string, value interface{}) {
+	// errors and durations marshal to unhelpful values so log their string form
+	switch v := value.(type) {
+	case error:
+		value = v.Error()
+	case time.Duration:
+		value = v.String()
+	case fmt.Stringer:
+		value = v.String()
+	}
+
+	k, _ := json.Marshal(key)
+	b.Write(k)
+	b.WriteByte(':')
+
+	v, err := json.Marshal(value)
+	if err != nil {
+		v, _ = json.Marshal(fmt.Sprint(value))
+	}
+	b.Write(v)
+}
+
+var (
+	defaultMutex  sync.RWMutex
+	defaultLogger = New(os.Stderr)
+)
+
+// Default returns the logger used when a context carries no logger
+func Default() *Logger {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+
+	return defaultLogger
+}
+
+// SetDefault replaces the logger used when a context carries no logger
+func SetDefault(l *Logger) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+
+	defaultLogger = l
+}
+
+type loggerKey struct{}
+
+// NewContext returns a context carrying l, it is used by everything handling a request so their lines share the request ID
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx or the default logger if there is none
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*Logger); ok {
+		return l
+	}
+
+	return Default()
+}
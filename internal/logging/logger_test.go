@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lines decodes every JSON line written to b
+func lines(t *testing.T, b *bytes.Buffer) []map[string]interface{} {
+	var decoded []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &fields), line)
+		decoded = append(decoded, fields)
+	}
+	return decoded
+}
+
+func Test_Logger(t *testing.T) {
+	t.Run("should write one JSON object per line with its level, message and fields", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := New(&b)
+		logger.now = func() time.Time { return time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+		logger.Info("fetched feed", "feed_url", "https://some-host.com/rss", "articles", 3)
+		logger.Error("failed to fetch feed", "error", errors.New("some error"), "timeout", 10*time.Second)
+
+		got := lines(t, &b)
+		require.Len(t, got, 2)
+		assert.Equal(t, map[string]interface{}{
+			"time":     "2022-01-02T03:04:05Z",
+			"level":    "info",
+			"msg":      "fetched feed",
+			"feed_url": "https://some-host.com/rss",
+			"articles": float64(3),
+		}, got[0])
+		assert.Equal(t, "error", got[1]["level"])
+		assert.Equal(t, "some error", got[1]["error"])
+		assert.Equal(t, "10s", got[1]["timeout"])
+	})
+
+	t.Run("should add fields from With to every line without changing the parent", func(t *testing.T) {
+		var b bytes.Buffer
+		parent := New(&b)
+		child := parent.With("request_id", "some-id")
+
+		child.Warn("some message", "key", "value")
+		parent.Info("other message")
+
+		got := lines(t, &b)
+		require.Len(t, got, 2)
+		assert.Equal(t, "some-id", got[0]["request_id"])
+		assert.Equal(t, "value", got[0]["key"])
+		assert.Equal(t, "warn", got[0]["level"])
+		assert.NotContains(t, got[1], "request_id")
+	})
+
+	t.Run("should escape keys and values and mark a key without a value", func(t *testing.T) {
+		var b bytes.Buffer
+		New(&b).Info("quote \" and newline \n", "odd")
+
+		got := lines(t, &b)
+		require.Len(t, got, 1)
+		assert.Equal(t, "quote \" and newline \n", got[0]["msg"])
+		assert.Equal(t, "MISSING", got[0]["odd"])
+	})
+}
+
+func Test_FromContext(t *testing.T) {
+	t.Run("should return the logger carried by the context", func(t *testing.T) {
+		logger := New(&bytes.Buffer{})
+
+		assert.Same(t, logger, FromContext(NewContext(context.Background(), logger)))
+	})
+
+	t.Run("should fall back to the default logger", func(t *testing.T) {
+		logger := New(&bytes.Buffer{})
+		previous := Default()
+		SetDefault(logger)
+		defer SetDefault(previous)
+
+		assert.Same(t, logger, FromContext(context.Background()))
+	})
+}
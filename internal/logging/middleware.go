@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/jonboulle/clockwork"
+)
+
+// RequestIDHeader carries the ID of a request between services and back to the client
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limits the request IDs accepted from clients so they are safe to log and echo back
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// requestFields holds fields handlers add to the line logged for their request
+type requestFields struct {
+	mutex  sync.Mutex
+	fields []interface{}
+}
+
+type requestFieldsKey struct{}
+
+// AddRequestFields adds key value pairs to the line logged when the request carried by ctx completes, it does nothing outside a request
+func AddRequestFields(ctx context.Context, keysAndValues ...interface{}) {
+	if f, ok := ctx.Value(requestFieldsKey{}).(*requestFields); ok {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+
+		f.fields = append(f.fields, keysAndValues...)
+	}
+}
+
+// Middleware gives every request an ID, taken from the X-Request-ID header when the client sends one, and a logger carrying it in the
+// request context. A line is logged for every request once it completes.
+func Middleware(logger *Logger, clock clockwork.Clock) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := clock.Now()
+
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID.MatchString(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			requestLogger := logger.With("request_id", requestID)
+			fields := &requestFields{}
+			ctx := NewContext(r.Context(), requestLogger)
+			ctx = context.WithValue(ctx, requestFieldsKey{}, fields)
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r.WithContext(ctx))
+
+			route := "unknown"
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			fields.mutex.Lock()
+			keysAndValues := append([]interface{}{
+				"method", r.Method,
+				"route", route,
+				"path", r.URL.Path,
+				"status", sw.status,
+				"duration_ms", float64(clock.Since(start).Microseconds()) / 1000,
+			}, fields.fields...)
+			fields.mutex.Unlock()
+
+			if sw.status >= http.StatusInternalServerError {
+				requestLogger.Error("request failed", keysAndValues...)
+			} else {
+				requestLogger.Info("request completed", keysAndValues...)
+			}
+		})
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// statusWriter remembers the status code written to a response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Middleware(t *testing.T) {
+	newRouter := func(b *bytes.Buffer, clock clockwork.Clock, handler http.HandlerFunc) *mux.Router {
+		router := mux.NewRouter()
+		router.HandleFunc("/feeds/{id}", handler).Methods(http.MethodGet)
+		router.Use(Middleware(New(b), clock))
+		return router
+	}
+
+	t.Run("should log the request with its route, status, duration and fields added by the handler", func(t *testing.T) {
+		var b bytes.Buffer
+		clock := clockwork.NewFakeClock()
+		router := newRouter(&b, clock, func(w http.ResponseWriter, r *http.Request) {
+			FromContext(r.Context()).Info("handling")
+			AddRequestFields(r.Context(), "feed_url", "https://some-host.com/rss")
+			clock.Advance(1500 * time.Microsecond)
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/feeds/some-id", nil)
+		req.Header.Set(RequestIDHeader, "some-request-id")
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, "some-request-id", res.Header().Get(RequestIDHeader))
+
+		got := lines(t, &b)
+		require.Len(t, got, 2)
+		assert.Equal(t, "handling", got[0]["msg"])
+		assert.Equal(t, "some-request-id", got[0]["request_id"])
+
+		assert.Equal(t, "info", got[1]["level"])
+		assert.Equal(t, "request completed", got[1]["msg"])
+		assert.Equal(t, "some-request-id", got[1]["request_id"])
+		assert.Equal(t, http.MethodGet, got[1]["method"])
+		assert.Equal(t, "/feeds/{id}", got[1]["route"])
+		assert.Equal(t, "/feeds/some-id", got[1]["path"])
+		assert.Equal(t, float64(http.StatusNotFound), got[1]["status"])
+		assert.Equal(t, 1.5, got[1]["duration_ms"])
+		assert.Equal(t, "https://some-host.com/rss", got[1]["feed_url"])
+	})
+
+	t.Run("should generate a request ID when the client sends none or an invalid one", func(t *testing.T) {
+		for _, requestID := range []string{"", "has spaces", "new\nline"} {
+			var b bytes.Buffer
+			router := newRouter(&b, clockwork.NewFakeClock(), func(w http.ResponseWriter, r *http.Request) {})
+
+			req := httptest.NewRequest(http.MethodGet, "/feeds/some-id", nil)
+			req.Header.Set(RequestIDHeader, requestID)
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			generated := res.Header().Get(RequestIDHeader)
+			assert.Regexp(t, "^[0-9a-f]{32}$", generated)
+
+			got := lines(t, &b)
+			require.Len(t, got, 1)
+			assert.Equal(t, generated, got[0]["request_id"])
+		}
+	})
+
+	t.Run("should log server errors at error level", func(t *testing.T) {
+		var b bytes.Buffer
+		router := newRouter(&b, clockwork.NewFakeClock(), func(w http.ResponseWriter, r *http.Request) {
+			AddRequestFields(r.Context(), "error", errors.New("some error"))
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/feeds/some-id", nil))
+
+		got := lines(t, &b)
+		require.Len(t, got, 1)
+		assert.Equal(t, "error", got[0]["level"])
+		assert.Equal(t, "request failed", got[0]["msg"])
+		assert.Equal(t, "some error", got[0]["error"])
+	})
+}
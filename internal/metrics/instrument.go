@@ -0,0 +1,307 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jonboulle/clockwork"
+
+	"news-app/internal/cache"
+	"news-app/internal/domain"
+	"news-app/internal/parser"
+	"news-app/internal/service"
+)
+
+// maxFeedHosts is how many feed hosts get series of their own. Feed URLs come from clients so any further hosts share
+// the other label rather than adding series without limit.
+const maxFeedHosts = 256
+
+// instrumentedParser records how long fetching each feed takes and why fetches fail
+type instrumentedParser struct {
+	parser.UniversalParser
+	clock    clockwork.Clock
+	duration *HistogramVec
+	failures *CounterVec
+	hosts    *hostLabels
+}
+
+// InstrumentParser wraps a parser to record fetch duration and failure reasons per feed host
+func InstrumentParser(p parser.UniversalParser, registry *Registry, clock clockwork.Clock) parser.UniversalParser {
+	return &instrumentedParser{
+		UniversalParser: p,
+		clock:           clock,
+		hosts:           newHostLabels(),
+		duration:        registry.NewHistogramVec("news_feed_fetch_duration_seconds", "Time taken to fetch and parse a feed.", DefaultBuckets, "host"),
+		failures:        registry.NewCounterVec("news_feed_fetch_failures_total", "Feed fetches that failed by reason.", "host", "reason"),
+	}
+}
+
+func (p *instrumentedParser) Parse(ctx context.Context, feedURL string) (domain.Feed, error) {
+	start := p.clock.Now()
+	feed, err := p.UniversalParser.Parse(ctx, feedURL)
+
+	host := p.hosts.label(feedHost(feedURL))
+	p.duration.Observe(p.clock.Since(start).Seconds(), host)
+	if err != nil && !errors.Is(err, parser.ErrNotModified) {
+		p.failures.Inc(host, failureReason(err))
+	}
+
+	return feed, err
+}
+
+// hostLabels remembers the feed hosts that have series of their own
+type hostLabels struct {
+	mutex sync.Mutex
+	seen  map[string]bool
+}
+
+func newHostLabels() *hostLabels {
+	return &hostLabels{seen: make(map[string]bool)}
+}
+
+// label returns host to label its series with, or other once maxFeedHosts other hosts have been seen
+func (h *hostLabels) label(host string) string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.seen[host] {
+		return host
+	}
+	if len(h.seen) >= maxFeedHosts {
+		return "other"
+	}
+	h.seen[host] = true
+	return host
+}
+
+func feedHost(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil || u.Host == "" {
+		return "invalid"
+	}
+
+	return strings.ToLower(u.Host)
+}
+
+// failureReasons are the labels for each kind of domain.FetchError
+var failureReasons = map[error]string{
+	domain.ErrInvalidFeedURL:    "invalid_url",
+	domain.ErrBlockedURL:        "blocked",
+	domain.ErrFeedUnreachable:   "network",
+	domain.ErrFeedTimeout:       "timeout",
+	domain.ErrHostThrottled:     "throttled",
+	domain.ErrCircuitOpen:       "circuit_open",
+	domain.ErrFeedTooLarge:      "too_large",
+	domain.ErrMalformedFeed:     "malformed",
+	domain.ErrUnsupportedFormat: "unsupported_format",
+}
+
+// failureReason groups fetch errors into a small set of reasons so they can be used as a label
+func failureReason(err error) string {
+	var fetchErr *domain.FetchError
+	switch {
+	case errors.As(err, &fetchErr):
+		if fetchErr.Kind == domain.ErrUpstreamStatus {
+			return "http_" + strconv.Itoa(fetchErr.StatusCode)
+		}
+		if reason, ok := failureReasons[fetchErr.Kind]; ok {
+			return reason
+		}
+		return "other"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "other"
+	}
+}
+
+// instrumentedHostLimiter counts fetches held back by the host limiter
+type instrumentedHostLimiter struct {
+	parser.HostLimiter
+	throttled *CounterVec
+	hosts     *hostLabels
+}
+
+// InstrumentHostLimiter wraps a host limiter to count throttled fetches per host, the state of each host is read from Hosts when scraped.
+// The gauges and the counter share their host labels, hosts past maxFeedHosts are combined into other.
+func InstrumentHostLimiter(l parser.HostLimiter, registry *Registry, clock clockwork.Clock) parser.HostLimiter {
+	hosts := newHostLabels()
+	// combine merges the values of hosts that share a label
+	hostGauge := func(name, help string, value func(parser.HostState) float64, combine func(a, b float64) float64) {
+		registry.NewGaugeVecFunc(name, help, "host", func() map[string]float64 {
+			values := make(map[string]float64)
+			for _, state := range l.Hosts() {
+				host := hosts.label(state.Host)
+				if current, ok := values[host]; ok {
+					values[host] = combine(current, value(state))
+					continue
+				}
+				values[host] = value(state)
+			}
+			return values
+		})
+	}
+	sum := func(a, b float64) float64 { return a + b }
+
+	hostGauge("news_feed_host_in_flight", "Fetches in progress or waiting for their turn per feed host.", func(state parser.HostState) float64 {
+		return float64(state.InFlight)
+	}, sum)
+	hostGauge("news_feed_host_failures", "Fetches in a row that failed because of the feed host.", func(state parser.HostState) float64 {
+		return float64(state.Failures)
+	}, math.Max)
+	hostGauge("news_feed_host_backoff_seconds", "Time until fetches from a failing feed host are allowed again.", func(state parser.HostState) float64 {
+		if state.BackoffUntil.IsZero() {
+			return 0
+		}
+		return math.Max(0, state.BackoffUntil.Sub(clock.Now()).Seconds())
+	}, math.Max)
+
+	return &instrumentedHostLimiter{
+		HostLimiter: l,
+		throttled:   registry.NewCounterVec("news_feed_host_throttled_total", "Fetches held back because their feed host was backed off or busy.", "host"),
+		hosts:       hosts,
+	}
+}
+
+func (l *instrumentedHostLimiter) Fetch(ctx context.Context, req parser.FetchRequest) (parser.FetchResponse, error) {
+	res, err := l.HostLimiter.Fetch(ctx, req)
+	if errors.Is(err, domain.ErrHostThrottled) {
+		// label by host name without the port like the limiter does, so the counter lines up with the gauges read from Hosts
+		host := "invalid"
+		if u, err := url.Parse(req.URL); err == nil && u.Hostname() != "" {
+			host = strings.ToLower(u.Hostname())
+		}
+		l.throttled.Inc(l.hosts.label(host))
+	}
+
+	return res, err
+}
+
+// instrumentedCache counts lookups by the freshness of what was found
+type instrumentedCache struct {
+	cache.Cache
+	lookups *CounterVec
+}
+
+// InstrumentCache wraps a cache to count hits and misses, the size of the cache and its evictions are read from Stats when scraped.
+// Stats is read once per scrape since it can be costly, the redis cache scans its keys.
+func InstrumentCache(c cache.Cache, registry *Registry) cache.Cache {
+	var mutex sync.Mutex
+	var stats cache.Stats
+	registry.BeforeScrape(func() {
+		current := c.Stats()
+		mutex.Lock()
+		stats = current
+		mutex.Unlock()
+	})
+	scraped := func() cache.Stats {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return stats
+	}
+
+	registry.NewGaugeFunc("news_cache_entries", "Feeds held in the cache.", func() float64 {
+		return float64(scraped().Entries)
+	})
+	registry.NewGaugeFunc("news_cache_bytes", "Approximate size of the articles held in the cache.", func() float64 {
+		return float64(scraped().Bytes)
+	})
+	registry.NewCounterFunc("news_cache_evictions_total", "Feeds evicted from the cache to stay within its limits.", func() float64 {
+		return float64(scraped().Evictions)
+	})
+
+	return &instrumentedCache{
+		Cache:   c,
+		lookups: registry.NewCounterVec("news_cache_lookups_total", "Cache lookups by result, fresh and stale are hits.", "result"),
+	}
+}
+
+func (c *instrumentedCache) GetArticlesFromCache(url string) ([]domain.Article, cache.Status, time.Duration) {
+	articles, status, freshFor := c.Cache.GetArticlesFromCache(url)
+
+	switch status {
+	case cache.Fresh:
+		c.lookups.Inc("fresh")
+	case cache.Stale:
+		c.lookups.Inc("stale")
+	default:
+		c.lookups.Inc("miss")
+	}
+
+	return articles, status, freshFor
+}
+
+// instrumentedService records calls to GetArticles
+type instrumentedService struct {
+	service.Service
+	clock    clockwork.Clock
+	calls    *CounterVec
+	duration *HistogramVec
+}
+
+// InstrumentService wraps a service to count GetArticles calls by result and record how long they take
+func InstrumentService(s service.Service, registry *Registry, clock clockwork.Clock) service.Service {
+	return &instrumentedService{
+		Service:  s,
+		clock:    clock,
+		calls:    registry.NewCounterVec("news_get_articles_total", "Calls to get the articles of a feed by result.", "result"),
+		duration: registry.NewHistogramVec("news_get_articles_duration_seconds", "Time taken to get the articles of a feed.", DefaultBuckets),
+	}
+}
+
+func (s *instrumentedService) GetArticles(ctx context.Context, feedURL string, page domain.PageRequest) (domain.ArticlePage, error) {
+	start := s.clock.Now()
+	articles, err := s.Service.GetArticles(ctx, feedURL, page)
+
+	s.duration.Observe(s.clock.Since(start).Seconds())
+	if err != nil {
+		s.calls.Inc("error")
+	} else {
+		s.calls.Inc("ok")
+	}
+
+	return articles, err
+}
+
+// Middleware records the duration of every request handled by a route by its path template, method and status
+func Middleware(registry *Registry, clock clockwork.Clock) mux.MiddlewareFunc {
+	duration := registry.NewHistogramVec("news_http_request_duration_seconds", "Time taken to serve HTTP requests.", DefaultBuckets, "route", "method", "status")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := clock.Now()
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(sw, r)
+
+			route := "unknown"
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+			duration.Observe(clock.Since(start).Seconds(), route, r.Method, strconv.Itoa(sw.status))
+		})
+	}
+}
+
+// statusWriter remembers the status code written to a response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,208 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"news-app/internal/cache"
+	"news-app/internal/domain"
+	"news-app/internal/parser"
+	"news-app/internal/service"
+)
+
+func written(t *testing.T, registry *Registry) string {
+	var b strings.Builder
+	_, err := registry.WriteTo(&b)
+	require.NoError(t, err)
+	return b.String()
+}
+
+func Test_InstrumentParser(t *testing.T) {
+	const someFeedURL = "https://some-host.com/rss"
+
+	t.Run("should record fetch duration and failure reasons by host", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		clock := clockwork.NewFakeClock()
+		registry := NewRegistry()
+		instrumented := InstrumentParser(mockParser, registry, clock)
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).DoAndReturn(func(context.Context, string) (domain.Feed, error) {
+			clock.Advance(300 * time.Millisecond)
+			return domain.Feed{}, nil
+		})
+		for _, err := range []error{
+			fmt.Errorf("failed to parse url: %w", &domain.FetchError{Kind: domain.ErrUpstreamStatus, StatusCode: 503}),
+			fmt.Errorf("failed to parse url: %w", &domain.FetchError{Kind: domain.ErrFeedTimeout, Err: context.DeadlineExceeded}),
+			&domain.FetchError{Kind: domain.ErrBlockedURL},
+			&domain.FetchError{Kind: domain.ErrFeedTooLarge},
+			&domain.FetchError{Kind: domain.ErrUnsupportedFormat},
+			context.Canceled,
+			assert.AnError,
+			parser.ErrNotModified,
+		} {
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, err)
+		}
+
+		for i := 0; i < 9; i++ {
+			_, _ = instrumented.Parse(context.Background(), someFeedURL)
+		}
+
+		out := written(t, registry)
+		assert.Contains(t, out, `news_feed_fetch_duration_seconds_bucket{host="some-host.com",le="0.25"} 8`)
+		assert.Contains(t, out, `news_feed_fetch_duration_seconds_count{host="some-host.com"} 9`)
+		for _, reason := range []string{"http_503", "timeout", "blocked", "too_large", "unsupported_format", "canceled", "other"} {
+			assert.Contains(t, out, `news_feed_fetch_failures_total{host="some-host.com",reason="`+reason+`"} 1`)
+		}
+	})
+}
+
+func Test_hostLabels(t *testing.T) {
+	t.Run("should label hosts past the maximum as other", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		registry := NewRegistry()
+		instrumented := InstrumentParser(mockParser, registry, clockwork.NewFakeClock())
+
+		mockParser.EXPECT().Parse(gomock.Any(), gomock.Any()).Return(domain.Feed{}, nil).AnyTimes()
+
+		for i := 0; i < maxFeedHosts+2; i++ {
+			_, _ = instrumented.Parse(context.Background(), fmt.Sprintf("https://host-%d.com/rss", i))
+		}
+		_, _ = instrumented.Parse(context.Background(), "https://HOST-0.com/other-rss")
+
+		out := written(t, registry)
+		assert.Contains(t, out, `news_feed_fetch_duration_seconds_count{host="host-0.com"} 2`)
+		assert.Contains(t, out, fmt.Sprintf(`news_feed_fetch_duration_seconds_count{host="host-%d.com"} 1`, maxFeedHosts-1))
+		assert.Contains(t, out, `news_feed_fetch_duration_seconds_count{host="other"} 2`)
+		assert.NotContains(t, out, fmt.Sprintf(`host="host-%d.com"`, maxFeedHosts))
+	})
+}
+
+func Test_InstrumentHostLimiter(t *testing.T) {
+	t.Run("should count throttled fetches and read the state of each host", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockLimiter := parser.NewMockHostLimiter(ctrl)
+		clock := clockwork.NewFakeClock()
+		registry := NewRegistry()
+		instrumented := InstrumentHostLimiter(mockLimiter, registry, clock)
+
+		someRequest := parser.FetchRequest{URL: "https://Some-Host.com:8443/rss"}
+		mockLimiter.EXPECT().Fetch(gomock.Any(), someRequest).Return(parser.FetchResponse{}, &domain.FetchError{Kind: domain.ErrHostThrottled})
+		mockLimiter.EXPECT().Fetch(gomock.Any(), someRequest).Return(parser.FetchResponse{}, nil)
+		mockLimiter.EXPECT().Hosts().Return([]parser.HostState{
+			{Host: "some-host.com", Failures: 2, BackoffUntil: clock.Now().Add(30 * time.Second)},
+			{Host: "other-host.com", InFlight: 1},
+		}).AnyTimes()
+
+		for i := 0; i < 2; i++ {
+			_, _ = instrumented.Fetch(context.Background(), someRequest)
+		}
+
+		out := written(t, registry)
+		assert.Contains(t, out, `news_feed_host_throttled_total{host="some-host.com"} 1`)
+		assert.Contains(t, out, `news_feed_host_in_flight{host="other-host.com"} 1`)
+		assert.Contains(t, out, `news_feed_host_failures{host="some-host.com"} 2`)
+		assert.Contains(t, out, `news_feed_host_backoff_seconds{host="some-host.com"} 30`)
+		assert.Contains(t, out, `news_feed_host_backoff_seconds{host="other-host.com"} 0`)
+	})
+	t.Run("should combine the state of hosts past the maximum into other", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockLimiter := parser.NewMockHostLimiter(ctrl)
+		clock := clockwork.NewFakeClock()
+		registry := NewRegistry()
+		InstrumentHostLimiter(mockLimiter, registry, clock)
+
+		states := make([]parser.HostState, 0, maxFeedHosts+2)
+		for i := 0; i < maxFeedHosts; i++ {
+			states = append(states, parser.HostState{Host: fmt.Sprintf("host-%d.com", i)})
+		}
+		states = append(states,
+			parser.HostState{Host: "some-host.com", InFlight: 2, Failures: 3, BackoffUntil: clock.Now().Add(10 * time.Second)},
+			parser.HostState{Host: "other-host.com", InFlight: 1, Failures: 5, BackoffUntil: clock.Now().Add(30 * time.Second)},
+		)
+		mockLimiter.EXPECT().Hosts().Return(states).AnyTimes()
+
+		out := written(t, registry)
+		assert.Contains(t, out, `news_feed_host_in_flight{host="other"} 3`)
+		assert.Contains(t, out, `news_feed_host_failures{host="other"} 5`)
+		assert.Contains(t, out, `news_feed_host_backoff_seconds{host="other"} 30`)
+		assert.NotContains(t, out, `host="some-host.com"`)
+		assert.Equal(t, maxFeedHosts+1, strings.Count(out, "news_feed_host_in_flight{"))
+	})
+}
+
+func Test_InstrumentCache(t *testing.T) {
+	t.Run("should count lookups by result and read the cache stats", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockCache := cache.NewMockCache(ctrl)
+		registry := NewRegistry()
+		instrumented := InstrumentCache(mockCache, registry)
+
+		mockCache.EXPECT().GetArticlesFromCache("some-url").Return(nil, cache.Fresh, time.Minute)
+		mockCache.EXPECT().GetArticlesFromCache("some-url").Return(nil, cache.Stale, time.Duration(0))
+		mockCache.EXPECT().GetArticlesFromCache("some-url").Return(nil, cache.Missing, time.Duration(0)).Times(2)
+		// once per scrape
+		mockCache.EXPECT().Stats().Return(cache.Stats{Entries: 2, Bytes: 100, Evictions: 5})
+
+		for i := 0; i < 4; i++ {
+			instrumented.GetArticlesFromCache("some-url")
+		}
+
+		out := written(t, registry)
+		assert.Contains(t, out, `news_cache_lookups_total{result="fresh"} 1`)
+		assert.Contains(t, out, `news_cache_lookups_total{result="stale"} 1`)
+		assert.Contains(t, out, `news_cache_lookups_total{result="miss"} 2`)
+		assert.Contains(t, out, "news_cache_entries 2\n")
+		assert.Contains(t, out, "news_cache_bytes 100\n")
+		assert.Contains(t, out, "news_cache_evictions_total 5\n")
+	})
+}
+
+func Test_InstrumentService(t *testing.T) {
+	t.Run("should count get articles calls by result", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		registry := NewRegistry()
+		instrumented := InstrumentService(mockService, registry, clockwork.NewFakeClock())
+
+		mockService.EXPECT().GetArticles(gomock.Any(), "some-url", gomock.Any()).Return(domain.ArticlePage{}, nil)
+		mockService.EXPECT().GetArticles(gomock.Any(), "some-url", gomock.Any()).Return(domain.ArticlePage{}, assert.AnError)
+
+		_, err := instrumented.GetArticles(context.Background(), "some-url", domain.PageRequest{})
+		assert.NoError(t, err)
+		_, err = instrumented.GetArticles(context.Background(), "some-url", domain.PageRequest{})
+		assert.ErrorIs(t, err, assert.AnError)
+
+		out := written(t, registry)
+		assert.Contains(t, out, `news_get_articles_total{result="ok"} 1`)
+		assert.Contains(t, out, `news_get_articles_total{result="error"} 1`)
+		assert.Contains(t, out, "news_get_articles_duration_seconds_count 2\n")
+	})
+}
+
+func Test_Middleware(t *testing.T) {
+	t.Run("should record request duration by route template, method and status", func(t *testing.T) {
+		registry := NewRegistry()
+		router := mux.NewRouter()
+		router.Use(Middleware(registry, clockwork.NewFakeClock()))
+		router.HandleFunc("/feeds/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/feeds/some-id", nil))
+
+		assert.Contains(t, written(t, registry), `news_http_request_duration_seconds_count{route="/feeds/{id}",method="GET",status="404"} 1`)
+	})
+}
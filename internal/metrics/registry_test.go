@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Registry(t *testing.T) {
+	write := func(t *testing.T, registry *Registry) string {
+		var b strings.Builder
+		_, err := registry.WriteTo(&b)
+		require.NoError(t, err)
+		return b.String()
+	}
+
+	t.Run("should write counters sorted by label values", func(t *testing.T) {
+		registry := NewRegistry()
+		counter := registry.NewCounterVec("some_total", "Some counter.", "code")
+
+		counter.Inc("500")
+		counter.Add(2, "200")
+		counter.Add(-1, "200")
+
+		assert.Equal(t, `# HELP some_total Some counter.
+# TYPE some_total counter
+some_total{code="200"} 2
+some_total{code="500"} 1
+`, write(t, registry))
+	})
+	t.Run("should write cumulative histogram buckets with a sum and count", func(t *testing.T) {
+		registry := NewRegistry()
+		histogram := registry.NewHistogramVec("some_seconds", "Some histogram.", []float64{0.1, 1}, "route")
+
+		histogram.Observe(0.05, "/some")
+		histogram.Observe(0.5, "/some")
+		histogram.Observe(2, "/some")
+
+		assert.Equal(t, `# HELP some_seconds Some histogram.
+# TYPE some_seconds histogram
+some_seconds_bucket{route="/some",le="0.1"} 1
+some_seconds_bucket{route="/some",le="1"} 2
+some_seconds_bucket{route="/some",le="+Inf"} 3
+some_seconds_sum{route="/some"} 2.55
+some_seconds_count{route="/some"} 3
+`, write(t, registry))
+	})
+	t.Run("should read func metrics when written and escape label values", func(t *testing.T) {
+		registry := NewRegistry()
+		value := 1.0
+		registry.NewGaugeFunc("some_gauge", "Some gauge.", func() float64 { return value })
+		counter := registry.NewCounterVec("some_escaped_total", "Some \\ help\nwith lines.", "value")
+		counter.Inc("a \"quoted\" \\ value\n")
+
+		value = 3
+
+		assert.Equal(t, `# HELP some_gauge Some gauge.
+# TYPE some_gauge gauge
+some_gauge 3
+# HELP some_escaped_total Some \\ help\nwith lines.
+# TYPE some_escaped_total counter
+some_escaped_total{value="a \"quoted\" \\ value\n"} 1
+`, write(t, registry))
+	})
+	t.Run("should read the series of gauge vec funcs when written", func(t *testing.T) {
+		registry := NewRegistry()
+		values := map[string]float64{"b": 2, "a": 1}
+		registry.NewGaugeVecFunc("some_gauge", "Some gauge.", "key", func() map[string]float64 { return values })
+
+		assert.Equal(t, `# HELP some_gauge Some gauge.
+# TYPE some_gauge gauge
+some_gauge{key="a"} 1
+some_gauge{key="b"} 2
+`, write(t, registry))
+
+		values = map[string]float64{}
+		assert.Equal(t, "# HELP some_gauge Some gauge.\n# TYPE some_gauge gauge\n", write(t, registry))
+	})
+	t.Run("should call the before scrape hooks before reading any metric", func(t *testing.T) {
+		registry := NewRegistry()
+		var value float64
+		registry.NewGaugeFunc("some_gauge", "Some gauge.", func() float64 { return value })
+		registry.BeforeScrape(func() { value++ })
+
+		assert.Equal(t, "# HELP some_gauge Some gauge.\n# TYPE some_gauge gauge\nsome_gauge 1\n", write(t, registry))
+		assert.Equal(t, "# HELP some_gauge Some gauge.\n# TYPE some_gauge gauge\nsome_gauge 2\n", write(t, registry))
+	})
+	t.Run("should serve metrics in the text exposition format", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.NewCounterVec("some_total", "Some counter.").Inc()
+
+		w := httptest.NewRecorder()
+		registry.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		assert.Equal(t, contentType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "some_total 1\n")
+	})
+}
@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"news-app/internal/domain"
+	"news-app/internal/logging"
+)
+
+// maxCircuits is how many failing feeds are tracked before feeds whose circuit is closed are forgotten
+const maxCircuits = 1024
+
+// NewCircuitBreaker is a constructor for a UniversalParser that stops fetching feeds that keep failing.
+// threshold represents how many calls for a feed fail in a row before its circuit opens and calls fail with domain.ErrCircuitOpen
+// coolDown represents how long the circuit stays open, after which a single call is let through to try the feed again.
+// The circuit closes when that call succeeds and opens for another coolDown when it fails.
+func NewCircuitBreaker(p UniversalParser, threshold int, coolDown time.Duration, clock clockwork.Clock) UniversalParser {
+	return &circuitBreaker{
+		UniversalParser: p,
+		threshold:       threshold,
+		coolDown:        coolDown,
+		clock:           clock,
+		circuits:        make(map[string]*circuit),
+	}
+}
+
+// circuitBreaker is the internal representation of a circuit breaking UniversalParser
+type circuitBreaker struct {
+	UniversalParser
+	threshold int
+	coolDown  time.Duration
+	clock     clockwork.Clock
+
+	mutex sync.Mutex
+	// circuits only holds feeds that are failing so it does not grow with every feed that was ever parsed
+	circuits map[string]*circuit
+}
+
+// circuit is the state kept for a failing feed, it is guarded by the breaker's mutex
+type circuit struct {
+	failures  int
+	openUntil time.Time
+	// trying is set while the call let through after the cool down is in progress
+	trying bool
+}
+
+func (b *circuitBreaker) Parse(ctx context.Context, url string) (domain.Feed, error) {
+	if err := b.allow(url); err != nil {
+		return domain.Feed{}, err
+	}
+
+	feed, err := b.UniversalParser.Parse(ctx, url)
+	b.record(ctx, url, err)
+
+	return feed, err
+}
+
+// allow returns domain.ErrCircuitOpen if the feed's circuit is open, marking the call as the trial once the cool down is over
+func (b *circuitBreaker) allow(url string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, ok := b.circuits[url]
+	if !ok || c.failures < b.threshold {
+		return nil
+	}
+
+	remaining := c.openUntil.Sub(b.clock.Now())
+	if remaining <= 0 && !c.trying {
+		c.trying = true
+		return nil
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &domain.FetchError{
+		Kind:       domain.ErrCircuitOpen,
+		RetryAfter: remaining,
+		Err:        fmt.Errorf("%s failed %d times in a row", url, c.failures),
+	}
+}
+
+// record updates the feed's circuit from the result of a call
+func (b *circuitBreaker) record(ctx context.Context, url string, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, ok := b.circuits[url]
+	if err == nil || errors.Is(err, ErrNotModified) {
+		if ok && c.failures >= b.threshold {
+			logging.FromContext(ctx).Info("feed circuit closed", "feed_url", url)
+		}
+		delete(b.circuits, url)
+		return
+	}
+
+	if !failedFeed(err) {
+		if ok {
+			c.trying = false
+		}
+		return
+	}
+
+	if !ok {
+		if len(b.circuits) >= maxCircuits {
+			b.forgetClosedCircuits()
+		}
+		c = &circuit{}
+		b.circuits[url] = c
+	}
+	c.failures++
+	c.trying = false
+
+	if c.failures >= b.threshold {
+		c.openUntil = b.clock.Now().Add(b.coolDown)
+		logging.FromContext(ctx).Warn("feed circuit opened", "feed_url", url, "failures", c.failures, "cool_down_ms", b.coolDown.Milliseconds(), "error", err)
+	}
+}
+
+// forgetClosedCircuits drops feeds that have not failed enough to open their circuit. b.mutex must be held.
+func (b *circuitBreaker) forgetClosedCircuits() {
+	for url, c := range b.circuits {
+		if c.failures < b.threshold {
+			delete(b.circuits, url)
+		}
+	}
+}
+
+// failedFeed returns whether err says something about the feed, rather than the call being canceled, held back or refused
+func failedFeed(err error) bool {
+	var fetchErr *domain.FetchError
+	if !errors.As(err, &fetchErr) {
+		return false
+	}
+
+	switch fetchErr.Kind {
+	case domain.ErrHostThrottled, domain.ErrCircuitOpen, domain.ErrBlockedURL, domain.ErrInvalidFeedURL:
+		return false
+	}
+	return !errors.Is(err, context.Canceled)
+}
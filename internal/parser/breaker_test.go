@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_circuitBreaker_Parse(t *testing.T) {
+	const (
+		someFeedURL  = "https://some-host.com/rss"
+		otherFeedURL = "https://some-host.com/other-rss"
+		someCoolDown = time.Minute
+	)
+
+	unreachable := &domain.FetchError{Kind: domain.ErrFeedUnreachable, Err: assert.AnError}
+
+	retryAfter := func(t *testing.T, err error) time.Duration {
+		var fetchErr *domain.FetchError
+		require.ErrorAs(t, err, &fetchErr)
+		require.ErrorIs(t, err, domain.ErrCircuitOpen)
+		return fetchErr.RetryAfter
+	}
+
+	t.Run("should fail fast for a feed that keeps failing until the cool down is over", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := NewMockUniversalParser(ctrl)
+		clock := clockwork.NewFakeClock()
+		breaker := NewCircuitBreaker(mockParser, 2, someCoolDown, clock)
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, unreachable).Times(3)
+		mockParser.EXPECT().Parse(gomock.Any(), otherFeedURL).Return(domain.Feed{}, nil)
+
+		for i := 0; i < 2; i++ {
+			_, err := breaker.Parse(context.Background(), someFeedURL)
+			assert.ErrorIs(t, err, domain.ErrFeedUnreachable)
+		}
+
+		clock.Advance(someCoolDown / 4)
+		_, err := breaker.Parse(context.Background(), someFeedURL)
+		assert.Equal(t, 3*someCoolDown/4, retryAfter(t, err))
+
+		// other feeds have their own circuit
+		_, err = breaker.Parse(context.Background(), otherFeedURL)
+		assert.NoError(t, err)
+
+		// the feed is tried again once the cool down is over and the circuit opens again when it still fails
+		clock.Advance(3 * someCoolDown / 4)
+		_, err = breaker.Parse(context.Background(), someFeedURL)
+		assert.ErrorIs(t, err, domain.ErrFeedUnreachable)
+
+		_, err = breaker.Parse(context.Background(), someFeedURL)
+		assert.Equal(t, someCoolDown, retryAfter(t, err))
+	})
+	t.Run("should close the circuit when the feed is fetched again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := NewMockUniversalParser(ctrl)
+		clock := clockwork.NewFakeClock()
+		breaker := NewCircuitBreaker(mockParser, 2, someCoolDown, clock)
+
+		gomock.InOrder(
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, unreachable).Times(2),
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, ErrNotModified),
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, unreachable),
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, nil),
+		)
+
+		for i := 0; i < 2; i++ {
+			_, _ = breaker.Parse(context.Background(), someFeedURL)
+		}
+		clock.Advance(someCoolDown)
+
+		_, err := breaker.Parse(context.Background(), someFeedURL)
+		assert.ErrorIs(t, err, ErrNotModified)
+
+		// the failures in a row start again from zero
+		_, err = breaker.Parse(context.Background(), someFeedURL)
+		assert.ErrorIs(t, err, domain.ErrFeedUnreachable)
+		_, err = breaker.Parse(context.Background(), someFeedURL)
+		assert.NoError(t, err)
+	})
+	t.Run("should only let one call try the feed after the cool down", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := NewMockUniversalParser(ctrl)
+		clock := clockwork.NewFakeClock()
+		breaker := NewCircuitBreaker(mockParser, 1, someCoolDown, clock)
+
+		trying := make(chan struct{})
+		release := make(chan struct{})
+		gomock.InOrder(
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, unreachable),
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).DoAndReturn(func(context.Context, string) (domain.Feed, error) {
+				close(trying)
+				<-release
+				return domain.Feed{}, nil
+			}),
+		)
+
+		_, _ = breaker.Parse(context.Background(), someFeedURL)
+		clock.Advance(someCoolDown)
+
+		done := make(chan error)
+		go func() {
+			_, err := breaker.Parse(context.Background(), someFeedURL)
+			done <- err
+		}()
+		<-trying
+
+		_, err := breaker.Parse(context.Background(), someFeedURL)
+		assert.Zero(t, retryAfter(t, err))
+
+		close(release)
+		assert.NoError(t, <-done)
+	})
+	t.Run("should not count failures that say nothing about the feed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := NewMockUniversalParser(ctrl)
+		breaker := NewCircuitBreaker(mockParser, 1, someCoolDown, clockwork.NewFakeClock())
+
+		for _, err := range []error{
+			&domain.FetchError{Kind: domain.ErrHostThrottled, RetryAfter: time.Second},
+			&domain.FetchError{Kind: domain.ErrBlockedURL},
+			&domain.FetchError{Kind: domain.ErrFeedTimeout, Err: context.Canceled},
+			context.Canceled,
+		} {
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, err).Times(2)
+
+			for i := 0; i < 2; i++ {
+				_, parseErr := breaker.Parse(context.Background(), someFeedURL)
+				assert.Equal(t, err, parseErr)
+			}
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"news-app/internal/domain"
+)
+
+// Fetcher is an interface for downloading feeds
+type Fetcher interface {
+	Fetch(ctx context.Context, req FetchRequest) (FetchResponse, error)
+}
+
+// FetchRequest describes a feed to download, ETag and LastModified are sent back to the publisher so it can answer 304 Not Modified
+type FetchRequest struct {
+	URL          string
+	ETag         string
+	LastModified string
+}
+
+// FetchResponse is a downloaded feed
+type FetchResponse struct {
+	Body []byte
+	// URL is where the feed was downloaded from once any redirects were followed
+	URL string
+	// MovedTo is set when the feed URL redirected permanently, it is the last URL reached through permanent redirects alone
+	MovedTo      string
+	ETag         string
+	LastModified string
+}
+
+// NewFetcher is a constructor for a Fetcher.
+// maxBodyBytes represents the largest feed that is downloaded, larger feeds fail with domain.ErrFeedTooLarge
+// hostHeaders represents headers sent to particular hosts, such as API keys, keyed by host name.
+// They are added to each request the client sends, redirects included, by the host that request goes to.
+func NewFetcher(client *http.Client, userAgent string, maxBodyBytes int64, hostHeaders map[string]http.Header) Fetcher {
+	if len(hostHeaders) > 0 {
+		headers := make(map[string]http.Header, len(hostHeaders))
+		for host, header := range hostHeaders {
+			headers[strings.ToLower(host)] = header
+		}
+
+		withHeaders := *client
+		withHeaders.Transport = &hostHeaderTransport{base: client.Transport, hostHeaders: headers}
+		client = &withHeaders
+	}
+
+	return &fetcher{
+		client:       client,
+		userAgent:    userAgent,
+		maxBodyBytes: maxBodyBytes,
+	}
+}
+
+// fetcher is the internal representation of a Fetcher
+type fetcher struct {
+	client       *http.Client
+	userAgent    string
+	maxBodyBytes int64
+}
+
+// hostHeaderTransport adds the headers configured for a host to each request sent to it. The client copies the headers of the
+// first request onto every redirect whatever host it leads to, so they are never set on the first request itself.
+type hostHeaderTransport struct {
+	base        http.RoundTripper
+	hostHeaders map[string]http.Header
+}
+
+func (t *hostHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	headers := t.hostHeaders[strings.ToLower(req.URL.Hostname())]
+	if len(headers) == 0 {
+		return base.RoundTrip(req)
+	}
+
+	// a RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	for name, values := range headers {
+		req.Header[http.CanonicalHeaderKey(name)] = values
+	}
+	return base.RoundTrip(req)
+}
+
+// Fetch downloads a feed, it returns ErrNotModified if the publisher reports it has not changed since the validators were sent
+func (f *fetcher) Fetch(ctx context.Context, fetchReq FetchRequest) (FetchResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fetchReq.URL, nil)
+	if err != nil {
+		return FetchResponse{}, &domain.FetchError{Kind: domain.ErrInvalidFeedURL, Err: err}
+	}
+	if (req.URL.Scheme != "http" && req.URL.Scheme != "https") || req.URL.Host == "" {
+		return FetchResponse{}, &domain.FetchError{
+			Kind: domain.ErrInvalidFeedURL,
+			Err:  fmt.Errorf("%q is not an absolute http or https url", fetchReq.URL),
+		}
+	}
+
+	req.Header.Set("User-Agent", f.userAgent)
+	if fetchReq.ETag != "" {
+		req.Header.Set("If-None-Match", fetchReq.ETag)
+	}
+	if fetchReq.LastModified != "" {
+		req.Header.Set("If-Modified-Since", fetchReq.LastModified)
+	}
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return FetchResponse{}, requestError(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified {
+		return FetchResponse{}, ErrNotModified
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return FetchResponse{}, &domain.FetchError{
+			Kind:       domain.ErrUpstreamStatus,
+			StatusCode: res.StatusCode,
+			RetryAfter: retryAfter(res.Header.Get("Retry-After")),
+			Err:        fmt.Errorf("publisher responded %s", res.Status),
+		}
+	}
+
+	// read one byte past the limit so a feed of exactly the limit is not mistaken for a larger one
+	body, err := io.ReadAll(io.LimitReader(res.Body, f.maxBodyBytes+1))
+	if err != nil {
+		return FetchResponse{}, requestError(err)
+	}
+	if int64(len(body)) > f.maxBodyBytes {
+		return FetchResponse{}, &domain.FetchError{
+			Kind: domain.ErrFeedTooLarge,
+			Err:  fmt.Errorf("feed is larger than %d bytes", f.maxBodyBytes),
+		}
+	}
+
+	return FetchResponse{
+		Body:         body,
+		URL:          res.Request.URL.String(),
+		MovedTo:      movedTo(res),
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// movedTo follows the redirects that led to res from the first request, it returns the URL reached by the leading permanent
+// redirects or an empty string if the first redirect was temporary or there were none
+func movedTo(res *http.Response) string {
+	// the client links each request to the redirect response that caused it, walk back to the first request
+	requests := []*http.Request{res.Request}
+	for req := res.Request; req.Response != nil; req = req.Response.Request {
+		requests = append(requests, req.Response.Request)
+	}
+
+	var moved string
+	for i := len(requests) - 2; i >= 0; i-- {
+		status := requests[i].Response.StatusCode
+		if status != http.StatusMovedPermanently && status != http.StatusPermanentRedirect {
+			break
+		}
+		moved = requests[i].URL.String()
+	}
+
+	return moved
+}
+
+// retryAfter reads a Retry-After header, which is either a number of seconds or a date, it returns zero if there is no usable value
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if until := time.Until(date); until > 0 {
+			return until
+		}
+	}
+	return 0
+}
+
+// requestError classifies an error from sending a request or reading its response. Cancellation is left as it is,
+// it means the caller went away rather than anything being wrong with the feed, as are URLs the client's policy blocked.
+func requestError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, domain.ErrBlockedURL):
+		return err
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return &domain.FetchError{Kind: domain.ErrFeedTimeout, Err: err}
+	default:
+		return &domain.FetchError{Kind: domain.ErrFeedUnreachable, Err: err}
+	}
+}
@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fetcher_Fetch(t *testing.T) {
+	const (
+		someUserAgent = "some-user-agent"
+		someMaxBytes  = 16
+	)
+
+	t.Run("should download a feed with the user agent and the headers for its host", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, someUserAgent, r.Header.Get("User-Agent"))
+			assert.Equal(t, "some-key", r.Header.Get("X-Api-Key"))
+			w.Header().Set("ETag", `"some-etag"`)
+			w.Header().Set("Last-Modified", "Sat, 01 Jan 2022 00:00:00 GMT")
+			_, _ = io.WriteString(w, "some-body")
+		}))
+		defer server.Close()
+
+		fetcher := NewFetcher(server.Client(), someUserAgent, someMaxBytes, map[string]http.Header{
+			"127.0.0.1":  {"x-api-key": {"some-key"}},
+			"other-host": {"X-Api-Key": {"other-key"}},
+		})
+
+		res, err := fetcher.Fetch(context.Background(), FetchRequest{URL: server.URL})
+		require.NoError(t, err)
+		assert.Equal(t, FetchResponse{
+			Body:         []byte("some-body"),
+			URL:          server.URL,
+			ETag:         `"some-etag"`,
+			LastModified: "Sat, 01 Jan 2022 00:00:00 GMT",
+		}, res)
+	})
+	t.Run("should only send the headers for a host to that host when following redirects", func(t *testing.T) {
+		wantOtherKey := []string{"other-key"}
+		other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, wantOtherKey, r.Header.Values("X-Api-Key"))
+			_, _ = io.WriteString(w, "some-body")
+		}))
+		defer other.Close()
+		// the same address under another host name
+		otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, []string{"some-key"}, r.Header.Values("X-Api-Key"))
+			http.Redirect(w, r, otherURL+"/rss", http.StatusFound)
+		}))
+		defer server.Close()
+
+		fetcher := NewFetcher(server.Client(), someUserAgent, someMaxBytes, map[string]http.Header{
+			"127.0.0.1": {"X-Api-Key": {"some-key"}},
+			"localhost": {"X-Api-Key": {"other-key"}},
+		})
+
+		res, err := fetcher.Fetch(context.Background(), FetchRequest{URL: server.URL})
+		require.NoError(t, err)
+		assert.Equal(t, otherURL+"/rss", res.URL)
+
+		// headers are not sent to hosts without any
+		fetcher = NewFetcher(server.Client(), someUserAgent, someMaxBytes, map[string]http.Header{
+			"127.0.0.1": {"X-Api-Key": {"some-key"}},
+		})
+		wantOtherKey = nil
+
+		_, err = fetcher.Fetch(context.Background(), FetchRequest{URL: server.URL})
+		require.NoError(t, err)
+	})
+	t.Run("should send validators and return ErrNotModified on a 304", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, `"some-etag"`, r.Header.Get("If-None-Match"))
+			assert.Equal(t, "Sat, 01 Jan 2022 00:00:00 GMT", r.Header.Get("If-Modified-Since"))
+			w.WriteHeader(http.StatusNotModified)
+		}))
+		defer server.Close()
+
+		fetcher := NewFetcher(server.Client(), someUserAgent, someMaxBytes, nil)
+
+		_, err := fetcher.Fetch(context.Background(), FetchRequest{
+			URL:          server.URL,
+			ETag:         `"some-etag"`,
+			LastModified: "Sat, 01 Jan 2022 00:00:00 GMT",
+		})
+		assert.ErrorIs(t, err, ErrNotModified)
+	})
+	t.Run("should return an error for feeds larger than the limit", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, strings.Repeat("a", someMaxBytes+1))
+		}))
+		defer server.Close()
+
+		fetcher := NewFetcher(server.Client(), someUserAgent, someMaxBytes, nil)
+
+		_, err := fetcher.Fetch(context.Background(), FetchRequest{URL: server.URL})
+		assert.ErrorIs(t, err, domain.ErrFeedTooLarge)
+
+		fetcher = NewFetcher(server.Client(), someUserAgent, someMaxBytes+1, nil)
+
+		res, err := fetcher.Fetch(context.Background(), FetchRequest{URL: server.URL})
+		require.NoError(t, err)
+		assert.Len(t, res.Body, someMaxBytes+1)
+	})
+	t.Run("should report the url reached through leading permanent redirects", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/old":
+				http.Redirect(w, r, "/older", http.StatusMovedPermanently)
+			case "/older":
+				http.Redirect(w, r, "/new", http.StatusPermanentRedirect)
+			case "/new":
+				http.Redirect(w, r, "/current", http.StatusFound)
+			case "/temporary":
+				http.Redirect(w, r, "/new", http.StatusTemporaryRedirect)
+			default:
+				_, _ = io.WriteString(w, "some-body")
+			}
+		}))
+		defer server.Close()
+
+		fetcher := NewFetcher(server.Client(), someUserAgent, someMaxBytes, nil)
+
+		res, err := fetcher.Fetch(context.Background(), FetchRequest{URL: server.URL + "/old"})
+		require.NoError(t, err)
+		assert.Equal(t, server.URL+"/current", res.URL)
+		assert.Equal(t, server.URL+"/new", res.MovedTo)
+
+		res, err = fetcher.Fetch(context.Background(), FetchRequest{URL: server.URL + "/temporary"})
+		require.NoError(t, err)
+		assert.Equal(t, server.URL+"/current", res.URL)
+		assert.Empty(t, res.MovedTo)
+
+		res, err = fetcher.Fetch(context.Background(), FetchRequest{URL: server.URL + "/current"})
+		require.NoError(t, err)
+		assert.Empty(t, res.MovedTo)
+	})
+	t.Run("should return an error if the publisher responds with an error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		fetcher := NewFetcher(server.Client(), someUserAgent, someMaxBytes, nil)
+
+		_, err := fetcher.Fetch(context.Background(), FetchRequest{URL: server.URL})
+		assert.ErrorIs(t, err, domain.ErrUpstreamStatus)
+		assert.EqualError(t, err, "feed returned an error status: publisher responded 500 Internal Server Error")
+
+		var fetchErr *domain.FetchError
+		assert.ErrorAs(t, err, &fetchErr)
+		assert.Equal(t, http.StatusInternalServerError, fetchErr.StatusCode)
+	})
+	t.Run("should return how long the publisher asked us to wait", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		fetcher := NewFetcher(server.Client(), someUserAgent, someMaxBytes, nil)
+
+		_, err := fetcher.Fetch(context.Background(), FetchRequest{URL: server.URL})
+		var fetchErr *domain.FetchError
+		require.ErrorAs(t, err, &fetchErr)
+		assert.Equal(t, http.StatusTooManyRequests, fetchErr.StatusCode)
+		assert.Equal(t, 2*time.Minute, fetchErr.RetryAfter)
+	})
+	t.Run("should return an error if the publisher cannot be reached", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		fetcher := NewFetcher(server.Client(), someUserAgent, someMaxBytes, nil)
+
+		_, err := fetcher.Fetch(context.Background(), FetchRequest{URL: server.URL})
+		assert.ErrorIs(t, err, domain.ErrFeedUnreachable)
+	})
+	t.Run("should return a timeout if the publisher does not respond in time", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		fetcher := NewFetcher(server.Client(), someUserAgent, someMaxBytes, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := fetcher.Fetch(ctx, FetchRequest{URL: server.URL})
+		assert.ErrorIs(t, err, domain.ErrFeedTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("should return an invalid url without making a request", func(t *testing.T) {
+		fetcher := NewFetcher(http.DefaultClient, someUserAgent, someMaxBytes, nil)
+
+		for _, url := range []string{"some-feed-url", "ftp://some-url.com/rss", "https://", "://"} {
+			_, err := fetcher.Fetch(context.Background(), FetchRequest{URL: url})
+			assert.ErrorIs(t, err, domain.ErrInvalidFeedURL, url)
+		}
+	})
+}
+
+func Test_retryAfter(t *testing.T) {
+	t.Run("should read seconds or a date", func(t *testing.T) {
+		assert.Equal(t, 30*time.Second, retryAfter("30"))
+		assert.Zero(t, retryAfter(""))
+		assert.Zero(t, retryAfter("-1"))
+		assert.Zero(t, retryAfter("soon"))
+		assert.Zero(t, retryAfter("Sat, 01 Jan 2022 00:00:00 GMT"))
+
+		until := retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		assert.InDelta(t, time.Hour, until, float64(2*time.Second))
+	})
+}
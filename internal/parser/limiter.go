@@ -0,0 +1,303 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"news-app/internal/domain"
+)
+
+// maxIdleHosts is how many hosts are tracked before hosts with nothing to remember are forgotten
+const maxIdleHosts = 1024
+
+// HostLimiter is a Fetcher that is polite to publishers, it limits how often and how many feeds are fetched from each host
+// and backs off from hosts that are failing or have asked us to slow down
+type HostLimiter interface {
+	Fetcher
+	Hosts() []HostState
+}
+
+// HostState describes how a host is being limited
+type HostState struct {
+	Host     string `json:"host"`
+	InFlight int    `json:"in_flight"`
+	// Failures is the number of fetches in a row that failed because of the host
+	Failures int `json:"failures"`
+	// BackoffUntil is when fetches from the host are allowed again, zero when it is not backed off
+	BackoffUntil time.Time `json:"backoff_until,omitempty"`
+}
+
+// NewHostLimiter is a constructor for a HostLimiter which fetches feeds with fetcher.
+// interval represents the time between fetches from a host once its burst has been used
+// burst represents how many fetches can be made from an idle host at once
+// maxConcurrent represents the most fetches from a host in progress at the same time
+// minBackoff and maxBackoff bound how long a failing host is left alone. The first failure in a row is forgiven so a blip
+// can be retried straight away, the backoff doubles with each failure after that. A Retry-After sent with a 429 or 503 is respected instead.
+func NewHostLimiter(fetcher Fetcher, interval time.Duration, burst, maxConcurrent int, minBackoff, maxBackoff time.Duration, clock clockwork.Clock) HostLimiter {
+	return &hostLimiter{
+		fetcher:       fetcher,
+		interval:      interval,
+		burst:         burst,
+		maxConcurrent: maxConcurrent,
+		minBackoff:    minBackoff,
+		maxBackoff:    maxBackoff,
+		clock:         clock,
+		hosts:         make(map[string]*host),
+	}
+}
+
+// hostLimiter is the internal representation of a HostLimiter
+type hostLimiter struct {
+	fetcher       Fetcher
+	interval      time.Duration
+	burst         int
+	maxConcurrent int
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+	clock         clockwork.Clock
+
+	mutex sync.Mutex
+	hosts map[string]*host
+}
+
+// host is the state kept for a single host, it is guarded by the limiter's mutex apart from slots
+type host struct {
+	// slots holds a value for every fetch in progress, pending also counts fetches waiting for a slot or token
+	slots   chan struct{}
+	pending int
+
+	// tokens is a token bucket refilled once per interval, it goes negative when fetches are waiting for a token
+	tokens   float64
+	refilled time.Time
+
+	failures     int
+	backoffUntil time.Time
+}
+
+// Fetch waits for a fetch slot and a token for the feed's host before fetching it.
+// It fails with domain.ErrHostThrottled straight away while the host is backed off, or if ctx ends while waiting.
+func (l *hostLimiter) Fetch(ctx context.Context, req FetchRequest) (FetchResponse, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil || u.Hostname() == "" {
+		// the fetcher reports invalid URLs
+		return l.fetcher.Fetch(ctx, req)
+	}
+	name := strings.ToLower(u.Hostname())
+
+	h, err := l.acquire(ctx, name)
+	if err != nil {
+		return FetchResponse{}, err
+	}
+
+	res, err := l.fetcher.Fetch(ctx, req)
+	l.release(h, err)
+
+	return res, err
+}
+
+// acquire takes a fetch slot and a token from the host, waiting for either if needed
+func (l *hostLimiter) acquire(ctx context.Context, name string) (*host, error) {
+	l.mutex.Lock()
+	h := l.host(name)
+	if err := l.checkBackoff(name, h); err != nil {
+		l.mutex.Unlock()
+		return nil, err
+	}
+	h.pending++
+	l.mutex.Unlock()
+
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		l.abandon(h, false)
+		return nil, throttled(0, fmt.Errorf("waiting to fetch from %s: %w", name, ctx.Err()))
+	}
+
+	l.mutex.Lock()
+	// the host may have been backed off while we waited for a slot
+	if err := l.checkBackoff(name, h); err != nil {
+		l.mutex.Unlock()
+		l.abandon(h, true)
+		return nil, err
+	}
+	wait := l.takeToken(h)
+	l.mutex.Unlock()
+
+	if wait <= 0 {
+		return h, nil
+	}
+
+	select {
+	case <-l.clock.After(wait):
+		return h, nil
+	case <-ctx.Done():
+		l.mutex.Lock()
+		h.tokens++
+		l.mutex.Unlock()
+		l.abandon(h, true)
+		return nil, throttled(0, fmt.Errorf("waiting to fetch from %s: %w", name, ctx.Err()))
+	}
+}
+
+// abandon gives up on a fetch before it was made, freeing its slot if it had taken one
+func (l *hostLimiter) abandon(h *host, slot bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	h.pending--
+	if slot {
+		<-h.slots
+	}
+}
+
+// release frees the slot taken by acquire and updates the host's backoff from the result of the fetch
+func (l *hostLimiter) release(h *host, err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	h.pending--
+	<-h.slots
+
+	var status int
+	var fetchErr *domain.FetchError
+	if errors.As(err, &fetchErr) && fetchErr.Kind == domain.ErrUpstreamStatus {
+		status = fetchErr.StatusCode
+	}
+
+	switch {
+	case err == nil, errors.Is(err, ErrNotModified):
+		h.failures = 0
+	case (status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable) && fetchErr.RetryAfter > 0:
+		h.failures++
+		h.backoffUntil = l.clock.Now().Add(fetchErr.RetryAfter)
+	case status == http.StatusTooManyRequests, status >= http.StatusInternalServerError,
+		errors.Is(err, domain.ErrFeedUnreachable), errors.Is(err, domain.ErrFeedTimeout):
+		h.failures++
+		if h.failures > 1 {
+			h.backoffUntil = l.clock.Now().Add(l.backoff(h.failures - 1))
+		}
+	case errors.Is(err, context.Canceled), errors.Is(err, domain.ErrBlockedURL), errors.Is(err, domain.ErrInvalidFeedURL):
+		// nothing was learned about the host
+	default:
+		// the host answered, whatever was wrong with the feed is not a reason to leave the host alone
+		h.failures = 0
+	}
+}
+
+// Hosts returns the state of every host that is being limited, sorted by host
+func (l *hostLimiter) Hosts() []HostState {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.clock.Now()
+	states := make([]HostState, 0, len(l.hosts))
+	for name, h := range l.hosts {
+		state := HostState{
+			Host:     name,
+			InFlight: len(h.slots),
+			Failures: h.failures,
+		}
+		if h.backoffUntil.After(now) {
+			state.BackoffUntil = h.backoffUntil
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Host < states[j].Host
+	})
+
+	return states
+}
+
+// host returns the state of a host, creating it if it is new. l.mutex must be held.
+func (l *hostLimiter) host(name string) *host {
+	h, ok := l.hosts[name]
+	if ok {
+		return h
+	}
+
+	if len(l.hosts) >= maxIdleHosts {
+		l.forgetIdleHosts()
+	}
+
+	h = &host{
+		slots:    make(chan struct{}, l.maxConcurrent),
+		tokens:   float64(l.burst),
+		refilled: l.clock.Now(),
+	}
+	l.hosts[name] = h
+	return h
+}
+
+// forgetIdleHosts drops hosts that would be recreated exactly as they are, so hosts seen once are not remembered forever.
+// l.mutex must be held.
+func (l *hostLimiter) forgetIdleHosts() {
+	now := l.clock.Now()
+	for name, h := range l.hosts {
+		l.refill(h, now)
+		if h.pending == 0 && h.failures == 0 && !h.backoffUntil.After(now) && h.tokens >= float64(l.burst) {
+			delete(l.hosts, name)
+		}
+	}
+}
+
+// checkBackoff returns domain.ErrHostThrottled if the host is backed off. l.mutex must be held.
+func (l *hostLimiter) checkBackoff(name string, h *host) error {
+	if remaining := h.backoffUntil.Sub(l.clock.Now()); remaining > 0 {
+		return throttled(remaining, fmt.Errorf("%s is backed off for %s", name, remaining.Round(time.Second)))
+	}
+	return nil
+}
+
+// takeToken takes a token from the host and returns how long to wait until it is available. l.mutex must be held.
+func (l *hostLimiter) takeToken(h *host) time.Duration {
+	l.refill(h, l.clock.Now())
+
+	h.tokens--
+	if h.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-h.tokens * float64(l.interval))
+}
+
+func (l *hostLimiter) refill(h *host, now time.Time) {
+	if l.interval <= 0 {
+		h.tokens = float64(l.burst)
+		return
+	}
+
+	h.tokens += float64(now.Sub(h.refilled)) / float64(l.interval)
+	if h.tokens > float64(l.burst) {
+		h.tokens = float64(l.burst)
+	}
+	h.refilled = now
+}
+
+// backoff doubles minBackoff for every failure after the first, up to maxBackoff
+func (l *hostLimiter) backoff(failures int) time.Duration {
+	backoff := l.minBackoff
+	for i := 1; i < failures && backoff < l.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > l.maxBackoff {
+		backoff = l.maxBackoff
+	}
+	return backoff
+}
+
+// throttled returns a domain.ErrHostThrottled, cancellation is left as it is since the caller went away
+func throttled(retryAfter time.Duration, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	return &domain.FetchError{Kind: domain.ErrHostThrottled, RetryAfter: retryAfter, Err: err}
+}
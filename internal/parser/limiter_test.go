@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_hostLimiter_Fetch(t *testing.T) {
+	const (
+		someURL      = "https://some-host.com/rss"
+		otherURL     = "https://SOME-HOST.com/other-rss"
+		anotherURL   = "https://another-host.com/rss"
+		someInterval = time.Second
+		someBackoff  = 10 * time.Second
+		maxBackoff   = 35 * time.Second
+	)
+
+	t.Run("should allow a burst of fetches from a host then space them by the interval", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		clock := clockwork.NewFakeClock()
+		limiter := NewHostLimiter(mockFetcher, someInterval, 2, 10, someBackoff, maxBackoff, clock)
+
+		mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(FetchResponse{}, nil).Times(4)
+
+		_, err := limiter.Fetch(context.Background(), FetchRequest{URL: someURL})
+		require.NoError(t, err)
+		_, err = limiter.Fetch(context.Background(), FetchRequest{URL: otherURL})
+		require.NoError(t, err)
+
+		// other hosts have their own burst
+		_, err = limiter.Fetch(context.Background(), FetchRequest{URL: anotherURL})
+		require.NoError(t, err)
+
+		done := make(chan error)
+		go func() {
+			_, err := limiter.Fetch(context.Background(), FetchRequest{URL: someURL})
+			done <- err
+		}()
+
+		clock.BlockUntil(1)
+		select {
+		case <-done:
+			t.Fatal("the fetch should wait for a token")
+		default:
+		}
+
+		clock.Advance(someInterval)
+		assert.NoError(t, <-done)
+	})
+	t.Run("should limit the fetches from a host in progress at once", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		limiter := NewHostLimiter(mockFetcher, someInterval, 10, 1, someBackoff, maxBackoff, clockwork.NewFakeClock())
+
+		fetching := make(chan struct{})
+		release := make(chan struct{})
+		mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL}).DoAndReturn(func(ctx context.Context, req FetchRequest) (FetchResponse, error) {
+			close(fetching)
+			<-release
+			return FetchResponse{}, nil
+		})
+		mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: anotherURL}).Return(FetchResponse{}, nil)
+
+		done := make(chan error)
+		go func() {
+			_, err := limiter.Fetch(context.Background(), FetchRequest{URL: someURL})
+			done <- err
+		}()
+		<-fetching
+
+		assert.Equal(t, []HostState{{Host: "some-host.com", InFlight: 1}}, limiter.Hosts())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := limiter.Fetch(ctx, FetchRequest{URL: someURL})
+		assert.ErrorIs(t, err, domain.ErrHostThrottled)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		_, err = limiter.Fetch(context.Background(), FetchRequest{URL: anotherURL})
+		assert.NoError(t, err)
+
+		close(release)
+		assert.NoError(t, <-done)
+	})
+	t.Run("should back off exponentially from a failing host until it succeeds", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		clock := clockwork.NewFakeClock()
+		limiter := NewHostLimiter(mockFetcher, 0, 10, 10, someBackoff, maxBackoff, clock)
+
+		unreachable := &domain.FetchError{Kind: domain.ErrFeedUnreachable, Err: assert.AnError}
+		serverError := &domain.FetchError{Kind: domain.ErrUpstreamStatus, StatusCode: http.StatusBadGateway}
+		gomock.InOrder(
+			mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(FetchResponse{}, unreachable),
+			mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(FetchResponse{}, unreachable),
+			mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(FetchResponse{}, serverError),
+			mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(FetchResponse{}, unreachable),
+			mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(FetchResponse{}, nil),
+			mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(FetchResponse{}, nil),
+		)
+
+		// a single failure is forgiven
+		_, err := limiter.Fetch(context.Background(), FetchRequest{URL: someURL})
+		assert.ErrorIs(t, err, domain.ErrFeedUnreachable)
+		assert.Equal(t, []HostState{{Host: "some-host.com", Failures: 1}}, limiter.Hosts())
+
+		for _, backoff := range []time.Duration{someBackoff, 2 * someBackoff, maxBackoff} {
+			_, err := limiter.Fetch(context.Background(), FetchRequest{URL: someURL})
+			require.Error(t, err)
+			assert.NotErrorIs(t, err, domain.ErrHostThrottled)
+
+			_, err = limiter.Fetch(context.Background(), FetchRequest{URL: otherURL})
+			assert.ErrorIs(t, err, domain.ErrHostThrottled)
+
+			var fetchErr *domain.FetchError
+			require.ErrorAs(t, err, &fetchErr)
+			assert.Equal(t, backoff, fetchErr.RetryAfter)
+
+			clock.Advance(backoff)
+		}
+
+		_, err = limiter.Fetch(context.Background(), FetchRequest{URL: someURL})
+		assert.NoError(t, err)
+		assert.Equal(t, []HostState{{Host: "some-host.com"}}, limiter.Hosts())
+
+		_, err = limiter.Fetch(context.Background(), FetchRequest{URL: someURL})
+		assert.NoError(t, err)
+	})
+	t.Run("should respect the Retry-After of a host that asks us to slow down", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		clock := clockwork.NewFakeClock()
+		limiter := NewHostLimiter(mockFetcher, 0, 10, 10, someBackoff, maxBackoff, clock)
+
+		mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(FetchResponse{}, &domain.FetchError{
+			Kind:       domain.ErrUpstreamStatus,
+			StatusCode: http.StatusTooManyRequests,
+			RetryAfter: time.Minute,
+		})
+
+		_, err := limiter.Fetch(context.Background(), FetchRequest{URL: someURL})
+		assert.ErrorIs(t, err, domain.ErrUpstreamStatus)
+
+		assert.Equal(t, []HostState{{Host: "some-host.com", Failures: 1, BackoffUntil: clock.Now().Add(time.Minute)}}, limiter.Hosts())
+
+		clock.Advance(time.Minute - time.Second)
+		_, err = limiter.Fetch(context.Background(), FetchRequest{URL: someURL})
+		assert.ErrorIs(t, err, domain.ErrHostThrottled)
+
+		var fetchErr *domain.FetchError
+		require.ErrorAs(t, err, &fetchErr)
+		assert.Equal(t, time.Second, fetchErr.RetryAfter)
+	})
+	t.Run("should not back off from hosts that answered", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		limiter := NewHostLimiter(mockFetcher, 0, 10, 10, someBackoff, maxBackoff, clockwork.NewFakeClock())
+
+		mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(FetchResponse{}, &domain.FetchError{Kind: domain.ErrUpstreamStatus, StatusCode: http.StatusNotFound}).Times(2)
+		mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(FetchResponse{}, ErrNotModified)
+		mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(FetchResponse{}, context.Canceled)
+
+		for i := 0; i < 2; i++ {
+			_, err := limiter.Fetch(context.Background(), FetchRequest{URL: someURL})
+			assert.ErrorIs(t, err, domain.ErrUpstreamStatus)
+		}
+		_, err := limiter.Fetch(context.Background(), FetchRequest{URL: someURL})
+		assert.ErrorIs(t, err, ErrNotModified)
+		_, err = limiter.Fetch(context.Background(), FetchRequest{URL: someURL})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		assert.Equal(t, []HostState{{Host: "some-host.com"}}, limiter.Hosts())
+	})
+	t.Run("should leave invalid urls to the fetcher", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		limiter := NewHostLimiter(mockFetcher, 0, 10, 10, someBackoff, maxBackoff, clockwork.NewFakeClock())
+
+		mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: "some-feed-url"}).Return(FetchResponse{}, &domain.FetchError{Kind: domain.ErrInvalidFeedURL})
+
+		_, err := limiter.Fetch(context.Background(), FetchRequest{URL: "some-feed-url"})
+		assert.ErrorIs(t, err, domain.ErrInvalidFeedURL)
+		assert.Empty(t, limiter.Hosts())
+	})
+}
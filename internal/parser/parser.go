@@ -1,54 +1,194 @@
-//go:generate mockgen -package=parser -destination=./parser_mock.go . InternalParser,UniversalParser
+//go:generate mockgen -package=parser -destination=./parser_mock.go . InternalParser,UniversalParser,Fetcher,HostLimiter
 
 package parser
 
 import (
+	"bytes"
+	"container/list"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"news-app/internal/domain"
+	"news-app/internal/logging"
+	"sync"
 	"time"
 
 	"github.com/mmcdole/gofeed"
 )
 
+// ErrNotModified is returned when the publisher reports the feed has not changed since it was last parsed
+var ErrNotModified = errors.New("feed not modified")
+
+// maxValidators is how many feeds the validators of their last fetch are kept for, the least recently fetched are forgotten first
+const maxValidators = 4096
+
 // UniversalParser is an interface for parsing RSS feeds
 type UniversalParser interface {
 	Parse(ctx context.Context, url string) (domain.Feed, error)
+	// Forget drops what was kept from the last fetch of a feed so the next one downloads it in full
+	Forget(url string)
 }
 
 // InternalParser an interface for mocking the gofeed package
 // The gofeed package provides a parser that works with RSS, ATOM and JSON feeds.
 type InternalParser interface {
-	ParseURLWithContext(string, context.Context) (*gofeed.Feed, error)
+	Parse(feed io.Reader) (*gofeed.Feed, error)
 }
 
 // NewParser is a constructor for creating a parser.
-func NewParser(timeout int, internalParser InternalParser) UniversalParser {
-	return parser{
+func NewParser(timeout time.Duration, fetcher Fetcher, internalParser InternalParser) UniversalParser {
+	return &parser{
 		timeout:        timeout,
+		fetcher:        fetcher,
 		internalParser: internalParser,
+		validators:     make(map[string]*list.Element),
+		lru:            list.New(),
 	}
 }
 
 // parser is the internal representation of an RSS parser
 type parser struct {
-	timeout        int
+	timeout        time.Duration
+	fetcher        Fetcher
 	internalParser InternalParser
+
+	mutex      sync.Mutex
+	validators map[string]*list.Element
+	// lru orders feeds from most to least recently fetched, each element holds a *validators
+	lru *list.List
+}
+
+// validators are the cache validators a publisher sent with a feed, they are sent back on the next fetch
+// so the publisher can answer 304 Not Modified instead of sending the whole feed again
+type validators struct {
+	url          string
+	etag         string
+	lastModified string
+}
+
+// Parse function will parse a feed from a FeedURL to a domain.Feed model.
+// It returns ErrNotModified if the feed has not changed since it was last parsed successfully.
+// Every fetch is logged with the logger carried by ctx.
+func (p *parser) Parse(ctx context.Context, url string) (domain.Feed, error) {
+	start := time.Now()
+	feed, err := p.parse(ctx, url)
+
+	logger := logging.FromContext(ctx)
+	duration := float64(time.Since(start).Microseconds()) / 1000
+	switch {
+	case errors.Is(err, ErrNotModified):
+		logger.Info("feed not modified", "feed_url", url, "duration_ms", duration)
+	case err != nil:
+		logger.Warn("failed to fetch feed", "feed_url", url, "duration_ms", duration, "error", err)
+	case feed.MovedTo != "":
+		logger.Info("fetched feed", "feed_url", url, "duration_ms", duration, "articles", len(feed.Articles), "moved_to", feed.MovedTo)
+	default:
+		logger.Info("fetched feed", "feed_url", url, "duration_ms", duration, "articles", len(feed.Articles))
+	}
+
+	return feed, err
 }
 
-// Parse function will parse a feed from a FeedURL to a domain.Feed model
-func (p parser) Parse(ctx context.Context, url string) (domain.Feed, error) {
+func (p *parser) parse(ctx context.Context, url string) (domain.Feed, error) {
 	// Set up a timeout on network call
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(p.timeout)*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
 	defer cancel()
 
-	// Call parser through Universal Parser interface so we can mock behaviour for testing
-	feed, err := p.internalParser.ParseURLWithContext(url, ctx)
+	v := p.lookup(url)
+
+	res, err := p.fetcher.Fetch(ctx, FetchRequest{
+		URL:          url,
+		ETag:         v.etag,
+		LastModified: v.lastModified,
+	})
+	if errors.Is(err, ErrNotModified) {
+		return domain.Feed{}, ErrNotModified
+	}
 	if err != nil {
 		return domain.Feed{}, fmt.Errorf("failed to parse url: %w", err)
 	}
 
-	return mapFeedToDomainModel(feed), nil
+	// Call parser through Universal Parser interface so we can mock behaviour for testing
+	feed, err := p.internalParser.Parse(bytes.NewReader(res.Body))
+	if err != nil {
+		return domain.Feed{}, fmt.Errorf("failed to parse url: %w", parseError(err))
+	}
+
+	// only remember validators once we have the feed they describe, a feed that moved is fetched from its new URL from now on
+	key := url
+	if res.MovedTo != "" {
+		key = res.MovedTo
+		p.Forget(url)
+	}
+	p.remember(validators{
+		url:          key,
+		etag:         res.ETag,
+		lastModified: res.LastModified,
+	})
+
+	parsed := mapFeedToDomainModel(feed)
+	parsed.MovedTo = res.MovedTo
+	return parsed, nil
+}
+
+// Forget drops the validators kept for a feed, such as once the articles they describe are gone
+func (p *parser) Forget(url string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if e, ok := p.validators[url]; ok {
+		p.remove(e)
+	}
+}
+
+// lookup returns the validators kept for a feed, they are empty if it has not been fetched or was forgotten
+func (p *parser) lookup(url string) validators {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	e, ok := p.validators[url]
+	if !ok {
+		return validators{}
+	}
+	p.lru.MoveToFront(e)
+
+	return *e.Value.(*validators)
+}
+
+// remember keeps the validators of a feed, forgetting the least recently fetched feeds once maxValidators are kept.
+// Publishers that send no validators leave nothing worth keeping.
+func (p *parser) remember(v validators) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if e, ok := p.validators[v.url]; ok {
+		p.remove(e)
+	}
+	if v.etag == "" && v.lastModified == "" {
+		return
+	}
+
+	p.validators[v.url] = p.lru.PushFront(&v)
+	for p.lru.Len() > maxValidators {
+		p.remove(p.lru.Back())
+	}
+}
+
+// remove drops an element from the validators. p.mutex must be held.
+func (p *parser) remove(e *list.Element) {
+	v := p.lru.Remove(e).(*validators)
+	delete(p.validators, v.url)
+}
+
+// parseError classifies an error from parsing a downloaded feed
+func parseError(err error) error {
+	if errors.Is(err, gofeed.ErrFeedTypeNotDetected) {
+		return &domain.FetchError{Kind: domain.ErrUnsupportedFormat, Err: err}
+	}
+	return &domain.FetchError{Kind: domain.ErrMalformedFeed, Err: err}
 }
 
 func mapFeedToDomainModel(f *gofeed.Feed) domain.Feed {
@@ -76,6 +216,7 @@ func mapItemToDomainModel(i *gofeed.Item) domain.Article {
 		}
 
 		return domain.Article{
+			ID:          articleID(i, published),
 			Title:       i.Title,
 			Description: i.Description,
 			Content:     i.Content,
@@ -88,6 +229,21 @@ func mapItemToDomainModel(i *gofeed.Item) domain.Article {
 	return domain.Article{}
 }
 
+// articleID builds a stable identity for an item so the same article can be recognised across fetches.
+// The GUID is preferred as publishers may change links, falling back to the link and then the title and published date.
+func articleID(i *gofeed.Item, published time.Time) string {
+	key := i.GUID
+	if key == "" {
+		key = i.Link
+	}
+	if key == "" {
+		key = i.Title + "|" + published.UTC().Format(time.RFC3339Nano)
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
 func mapImageToDomainModel(i *gofeed.Image) domain.Image {
 	if i != nil {
 		return domain.Image{
@@ -1,11 +1,12 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: news-app/internal/parser (interfaces: InternalParser,UniversalParser)
+// Source: news-app/internal/parser (interfaces: InternalParser,UniversalParser,Fetcher,HostLimiter)
 
 // Package parser is a generated GoMock package.
 package parser
 
 import (
 	context "context"
+	io "io"
 	domain "news-app/internal/domain"
 	reflect "reflect"
 
@@ -36,19 +37,19 @@ func (m *MockInternalParser) EXPECT() *MockInternalParserMockRecorder {
 	return m.recorder
 }
 
-// ParseURLWithContext mocks base method.
-func (m *MockInternalParser) ParseURLWithContext(arg0 string, arg1 context.Context) (*gofeed.Feed, error) {
+// Parse mocks base method.
+func (m *MockInternalParser) Parse(arg0 io.Reader) (*gofeed.Feed, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ParseURLWithContext", arg0, arg1)
+	ret := m.ctrl.Call(m, "Parse", arg0)
 	ret0, _ := ret[0].(*gofeed.Feed)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ParseURLWithContext indicates an expected call of ParseURLWithContext.
-func (mr *MockInternalParserMockRecorder) ParseURLWithContext(arg0, arg1 interface{}) *gomock.Call {
+// Parse indicates an expected call of Parse.
+func (mr *MockInternalParserMockRecorder) Parse(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseURLWithContext", reflect.TypeOf((*MockInternalParser)(nil).ParseURLWithContext), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Parse", reflect.TypeOf((*MockInternalParser)(nil).Parse), arg0)
 }
 
 // MockUniversalParser is a mock of UniversalParser interface.
@@ -74,6 +75,18 @@ func (m *MockUniversalParser) EXPECT() *MockUniversalParserMockRecorder {
 	return m.recorder
 }
 
+// Forget mocks base method.
+func (m *MockUniversalParser) Forget(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Forget", arg0)
+}
+
+// Forget indicates an expected call of Forget.
+func (mr *MockUniversalParserMockRecorder) Forget(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Forget", reflect.TypeOf((*MockUniversalParser)(nil).Forget), arg0)
+}
+
 // Parse mocks base method.
 func (m *MockUniversalParser) Parse(arg0 context.Context, arg1 string) (domain.Feed, error) {
 	m.ctrl.T.Helper()
@@ -88,3 +101,93 @@ func (mr *MockUniversalParserMockRecorder) Parse(arg0, arg1 interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Parse", reflect.TypeOf((*MockUniversalParser)(nil).Parse), arg0, arg1)
 }
+
+// MockFetcher is a mock of Fetcher interface.
+type MockFetcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockFetcherMockRecorder
+}
+
+// MockFetcherMockRecorder is the mock recorder for MockFetcher.
+type MockFetcherMockRecorder struct {
+	mock *MockFetcher
+}
+
+// NewMockFetcher creates a new mock instance.
+func NewMockFetcher(ctrl *gomock.Controller) *MockFetcher {
+	mock := &MockFetcher{ctrl: ctrl}
+	mock.recorder = &MockFetcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFetcher) EXPECT() *MockFetcherMockRecorder {
+	return m.recorder
+}
+
+// Fetch mocks base method.
+func (m *MockFetcher) Fetch(arg0 context.Context, arg1 FetchRequest) (FetchResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fetch", arg0, arg1)
+	ret0, _ := ret[0].(FetchResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fetch indicates an expected call of Fetch.
+func (mr *MockFetcherMockRecorder) Fetch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockFetcher)(nil).Fetch), arg0, arg1)
+}
+
+// MockHostLimiter is a mock of HostLimiter interface.
+type MockHostLimiter struct {
+	ctrl     *gomock.Controller
+	recorder *MockHostLimiterMockRecorder
+}
+
+// MockHostLimiterMockRecorder is the mock recorder for MockHostLimiter.
+type MockHostLimiterMockRecorder struct {
+	mock *MockHostLimiter
+}
+
+// NewMockHostLimiter creates a new mock instance.
+func NewMockHostLimiter(ctrl *gomock.Controller) *MockHostLimiter {
+	mock := &MockHostLimiter{ctrl: ctrl}
+	mock.recorder = &MockHostLimiterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHostLimiter) EXPECT() *MockHostLimiterMockRecorder {
+	return m.recorder
+}
+
+// Fetch mocks base method.
+func (m *MockHostLimiter) Fetch(arg0 context.Context, arg1 FetchRequest) (FetchResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fetch", arg0, arg1)
+	ret0, _ := ret[0].(FetchResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fetch indicates an expected call of Fetch.
+func (mr *MockHostLimiterMockRecorder) Fetch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockHostLimiter)(nil).Fetch), arg0, arg1)
+}
+
+// Hosts mocks base method.
+func (m *MockHostLimiter) Hosts() []HostState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hosts")
+	ret0, _ := ret[0].([]HostState)
+	return ret0
+}
+
+// Hosts indicates an expected call of Hosts.
+func (mr *MockHostLimiterMockRecorder) Hosts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hosts", reflect.TypeOf((*MockHostLimiter)(nil).Hosts))
+}
@@ -2,6 +2,8 @@ package parser
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"news-app/internal/domain"
 	"testing"
 	"time"
@@ -14,6 +16,7 @@ import (
 func Test_parser_Parse(t *testing.T) {
 	var (
 		someURL         = "https://some-url.com"
+		someGUID        = "some-guid"
 		someTitle       = "someTitle"
 		someDescription = "someDescription"
 		someContent     = "someContent"
@@ -23,6 +26,7 @@ func Test_parser_Parse(t *testing.T) {
 			Title: someTitle,
 		}
 		someItem = gofeed.Item{
+			GUID:            someGUID,
 			Title:           someTitle,
 			Description:     someDescription,
 			Content:         someContent,
@@ -38,11 +42,17 @@ func Test_parser_Parse(t *testing.T) {
 
 	t.Run("parser should return a feed", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
 		mockInternalParser := NewMockInternalParser(ctrl)
+		parser := NewParser(10*time.Second, mockFetcher, mockInternalParser)
 
-		parser := NewParser(10, mockInternalParser)
-
-		mockInternalParser.EXPECT().ParseURLWithContext(someURL, gomock.Any()).Return(&someFeed, nil)
+		mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL}).Return(FetchResponse{Body: []byte("some-body"), URL: someURL}, nil)
+		mockInternalParser.EXPECT().Parse(gomock.Any()).DoAndReturn(func(feed io.Reader) (*gofeed.Feed, error) {
+			body, err := io.ReadAll(feed)
+			assert.NoError(t, err)
+			assert.Equal(t, "some-body", string(body))
+			return &someFeed, nil
+		})
 
 		feed, err := parser.Parse(context.Background(), someURL)
 		assert.NoError(t, err)
@@ -52,6 +62,7 @@ func Test_parser_Parse(t *testing.T) {
 			Description: someDescription,
 			Articles: []domain.Article{
 				{
+					ID:          "c0c8d2461d9861615a5a8fb901315186",
 					Title:       someTitle,
 					Description: someDescription,
 					Content:     someContent,
@@ -65,16 +76,168 @@ func Test_parser_Parse(t *testing.T) {
 		}
 		assert.Equal(t, expected, feed)
 	})
-	t.Run("parser should return an error if we fail to parse FeedURL", func(t *testing.T) {
+	t.Run("parser should report a feed that has permanently moved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		mockInternalParser := NewMockInternalParser(ctrl)
+		parser := NewParser(10*time.Second, mockFetcher, mockInternalParser)
+
+		const someNewURL = "https://some-new-url.com"
+		mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL}).Return(FetchResponse{URL: someNewURL, MovedTo: someNewURL}, nil)
+		mockInternalParser.EXPECT().Parse(gomock.Any()).Return(&someFeed, nil)
+
+		feed, err := parser.Parse(context.Background(), someURL)
+		assert.NoError(t, err)
+		assert.Equal(t, someNewURL, feed.MovedTo)
+	})
+	t.Run("parser should keep the validators of a feed that has permanently moved under its new URL", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		mockInternalParser := NewMockInternalParser(ctrl)
+		parser := NewParser(10*time.Second, mockFetcher, mockInternalParser)
+
+		const someNewURL = "https://some-new-url.com"
+		gomock.InOrder(
+			mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL}).Return(FetchResponse{ETag: `"some-etag"`}, nil),
+			mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL, ETag: `"some-etag"`}).Return(FetchResponse{URL: someNewURL, MovedTo: someNewURL, ETag: `"some-new-etag"`}, nil),
+			// the old URL is fetched without validators so a 304 cannot stand for articles that are kept under the new one
+			mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL}).Return(FetchResponse{URL: someNewURL, MovedTo: someNewURL, ETag: `"some-new-etag"`}, nil),
+			mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someNewURL, ETag: `"some-new-etag"`}).Return(FetchResponse{}, ErrNotModified),
+		)
+		mockInternalParser.EXPECT().Parse(gomock.Any()).Return(&someFeed, nil).Times(3)
+
+		for i := 0; i < 3; i++ {
+			_, err := parser.Parse(context.Background(), someURL)
+			assert.NoError(t, err)
+		}
+
+		_, err := parser.Parse(context.Background(), someNewURL)
+		assert.ErrorIs(t, err, ErrNotModified)
+	})
+	t.Run("parser should send validators from the last fetch and return ErrNotModified", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		mockInternalParser := NewMockInternalParser(ctrl)
+		parser := NewParser(10*time.Second, mockFetcher, mockInternalParser)
+
+		const (
+			someETag         = `"some-etag"`
+			someLastModified = "Sat, 01 Jan 2022 00:00:00 GMT"
+		)
+		gomock.InOrder(
+			mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL}).Return(FetchResponse{ETag: someETag, LastModified: someLastModified}, nil),
+			mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL, ETag: someETag, LastModified: someLastModified}).Return(FetchResponse{}, ErrNotModified),
+		)
+		mockInternalParser.EXPECT().Parse(gomock.Any()).Return(&someFeed, nil)
+
+		_, err := parser.Parse(context.Background(), someURL)
+		assert.NoError(t, err)
+
+		feed, err := parser.Parse(context.Background(), someURL)
+		assert.ErrorIs(t, err, ErrNotModified)
+		assert.Empty(t, feed)
+	})
+	t.Run("parser should not send validators for a feed it was told to forget", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		mockInternalParser := NewMockInternalParser(ctrl)
+		parser := NewParser(10*time.Second, mockFetcher, mockInternalParser)
+
+		mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL}).Return(FetchResponse{ETag: `"some-etag"`}, nil).Times(2)
+		mockInternalParser.EXPECT().Parse(gomock.Any()).Return(&someFeed, nil).Times(2)
+
+		_, err := parser.Parse(context.Background(), someURL)
+		assert.NoError(t, err)
+
+		parser.Forget(someURL)
+
+		_, err = parser.Parse(context.Background(), someURL)
+		assert.NoError(t, err)
+	})
+	t.Run("parser should forget the validators of the least recently fetched feeds once it keeps too many", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		mockInternalParser := NewMockInternalParser(ctrl)
+		parser := NewParser(10*time.Second, mockFetcher, mockInternalParser)
+
+		var sent []FetchRequest
+		mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, req FetchRequest) (FetchResponse, error) {
+			sent = append(sent, req)
+			return FetchResponse{ETag: `"some-etag"`}, nil
+		}).AnyTimes()
+		mockInternalParser.EXPECT().Parse(gomock.Any()).Return(&someFeed, nil).AnyTimes()
+
+		for i := 0; i <= maxValidators; i++ {
+			_, err := parser.Parse(context.Background(), fmt.Sprintf("%s/%d", someURL, i))
+			assert.NoError(t, err)
+		}
+
+		sent = nil
+		_, err := parser.Parse(context.Background(), someURL+"/0")
+		assert.NoError(t, err)
+		_, err = parser.Parse(context.Background(), fmt.Sprintf("%s/%d", someURL, maxValidators))
+		assert.NoError(t, err)
+
+		assert.Equal(t, []FetchRequest{
+			{URL: someURL + "/0"},
+			{URL: fmt.Sprintf("%s/%d", someURL, maxValidators), ETag: `"some-etag"`},
+		}, sent)
+	})
+	t.Run("parser should not keep validators if the feed fails to parse", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
 		mockInternalParser := NewMockInternalParser(ctrl)
+		parser := NewParser(10*time.Second, mockFetcher, mockInternalParser)
+
+		mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL}).Return(FetchResponse{ETag: `"some-etag"`}, nil).Times(2)
+		mockInternalParser.EXPECT().Parse(gomock.Any()).Return(nil, assert.AnError).Times(2)
 
-		parser := NewParser(10, mockInternalParser)
+		_, err := parser.Parse(context.Background(), someURL)
+		assert.ErrorIs(t, err, assert.AnError)
 
-		mockInternalParser.EXPECT().ParseURLWithContext(someURL, gomock.Any()).Return(nil, assert.AnError)
+		_, err = parser.Parse(context.Background(), someURL)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+	t.Run("parser should return an error if the feed cannot be fetched", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		parser := NewParser(10*time.Second, mockFetcher, NewMockInternalParser(ctrl))
+
+		mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL}).Return(FetchResponse{}, &domain.FetchError{Kind: domain.ErrFeedUnreachable, Err: assert.AnError})
 
 		feed, err := parser.Parse(context.Background(), someURL)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrFeedUnreachable)
+		assert.ErrorIs(t, err, assert.AnError)
 		assert.Empty(t, feed)
 	})
+	t.Run("parser should give the fetch a deadline", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		parser := NewParser(10*time.Millisecond, mockFetcher, NewMockInternalParser(ctrl))
+
+		mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req FetchRequest) (FetchResponse, error) {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			<-ctx.Done()
+			return FetchResponse{}, requestError(ctx.Err())
+		})
+
+		_, err := parser.Parse(context.Background(), someURL)
+		assert.ErrorIs(t, err, domain.ErrFeedTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("parser should tell malformed feeds from unsupported formats", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFetcher := NewMockFetcher(ctrl)
+		parser := NewParser(10*time.Second, mockFetcher, gofeed.NewParser())
+
+		mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL + "/html"}).Return(FetchResponse{Body: []byte("<html><body>not a feed</body></html>")}, nil)
+		mockFetcher.EXPECT().Fetch(gomock.Any(), FetchRequest{URL: someURL + "/rss"}).Return(FetchResponse{Body: []byte("<rss><channel><title>unclosed")}, nil)
+
+		_, err := parser.Parse(context.Background(), someURL+"/html")
+		assert.ErrorIs(t, err, domain.ErrUnsupportedFormat)
+
+		_, err = parser.Parse(context.Background(), someURL+"/rss")
+		assert.ErrorIs(t, err, domain.ErrMalformedFeed)
+	})
 }
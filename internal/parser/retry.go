@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"news-app/internal/domain"
+	"news-app/internal/logging"
+)
+
+// NewRetryingParser is a constructor for a UniversalParser that parses a feed again when fetching it failed for a reason
+// that may soon pass, such as the publisher being unreachable or responding 502, 503 or 504.
+// timeout represents how long one call may take, attempts and the waits between them included. It should be a multiple of
+// the timeout of each attempt so a feed that timed out still has time to be fetched again.
+// attempts represents the most times a feed is fetched for one call, including the first
+// minDelay and maxDelay bound the wait between attempts, it doubles after each attempt and is jittered so callers spread out.
+// A retry is given up on when it would have to wait longer than maxDelay or past the timeout of the call.
+func NewRetryingParser(p UniversalParser, timeout time.Duration, attempts int, minDelay, maxDelay time.Duration, clock clockwork.Clock) UniversalParser {
+	return &retryingParser{
+		UniversalParser: p,
+		timeout:         timeout,
+		attempts:        attempts,
+		minDelay:        minDelay,
+		maxDelay:        maxDelay,
+		clock:           clock,
+	}
+}
+
+// retryingParser is the internal representation of a retrying UniversalParser
+type retryingParser struct {
+	UniversalParser
+	timeout  time.Duration
+	attempts int
+	minDelay time.Duration
+	maxDelay time.Duration
+	clock    clockwork.Clock
+}
+
+func (r *retryingParser) Parse(ctx context.Context, url string) (domain.Feed, error) {
+	// callers such as HTTP requests usually have no deadline of their own, so retries could otherwise outlast them
+	deadline := r.clock.Now().Add(r.timeout)
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	delay := r.minDelay
+	var feedErr error
+	for attempt := 1; ; attempt++ {
+		feed, err := r.UniversalParser.Parse(ctx, url)
+		if err == nil {
+			return feed, nil
+		}
+		// once the host limiter holds back a retry, what went wrong with the feed itself is what is worth reporting
+		if feedErr == nil || !errors.Is(err, domain.ErrHostThrottled) {
+			feedErr = err
+		}
+		if attempt >= r.attempts || ctx.Err() != nil {
+			return feed, feedErr
+		}
+
+		wait, ok := retryWait(err, jitter(delay))
+		if !ok || wait > r.maxDelay {
+			return feed, feedErr
+		}
+		if deadline.Sub(r.clock.Now()) <= wait {
+			return feed, feedErr
+		}
+
+		logging.FromContext(ctx).Info("retrying feed", "feed_url", url, "attempt", attempt+1, "delay_ms", wait.Milliseconds(), "error", err)
+
+		select {
+		case <-r.clock.After(wait):
+		case <-ctx.Done():
+			return feed, feedErr
+		}
+
+		delay *= 2
+		if delay > r.maxDelay {
+			delay = r.maxDelay
+		}
+	}
+}
+
+// retryWait returns how long to wait before fetching a feed again after err, and false if it should not be fetched again.
+// The publisher's or host limiter's Retry-After is waited for when it is longer than delay.
+func retryWait(err error, delay time.Duration) (time.Duration, bool) {
+	var fetchErr *domain.FetchError
+	if !errors.As(err, &fetchErr) {
+		return 0, false
+	}
+
+	switch fetchErr.Kind {
+	case domain.ErrFeedUnreachable, domain.ErrFeedTimeout:
+	case domain.ErrUpstreamStatus:
+		switch fetchErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		default:
+			return 0, false
+		}
+	case domain.ErrHostThrottled:
+		// without a Retry-After the host was too busy to fetch from before the call ended
+		if fetchErr.RetryAfter <= 0 {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if fetchErr.RetryAfter > delay {
+		return fetchErr.RetryAfter, true
+	}
+	return delay, true
+}
+
+// jitter returns a random duration between half of delay and delay
+func jitter(delay time.Duration) time.Duration {
+	if delay <= 1 {
+		return delay
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
@@ -0,0 +1,216 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_retryingParser_Parse(t *testing.T) {
+	const (
+		someFeedURL = "https://some-host.com/rss"
+		someTimeout = time.Minute
+		minDelay    = time.Second
+		maxDelay    = 10 * time.Second
+	)
+
+	someFeed := domain.Feed{Title: "some-title"}
+	unreachable := fmt.Errorf("failed to parse url: %w", &domain.FetchError{Kind: domain.ErrFeedUnreachable, Err: assert.AnError})
+	badGateway := &domain.FetchError{Kind: domain.ErrUpstreamStatus, StatusCode: http.StatusBadGateway}
+
+	type result struct {
+		feed domain.Feed
+		err  error
+	}
+	parse := func(ctx context.Context, p UniversalParser) chan result {
+		done := make(chan result, 1)
+		go func() {
+			feed, err := p.Parse(ctx, someFeedURL)
+			done <- result{feed, err}
+		}()
+		return done
+	}
+
+	t.Run("should fetch a feed again after a failure that may pass with a growing delay", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := NewMockUniversalParser(ctrl)
+		clock := clockwork.NewFakeClock()
+		retrying := NewRetryingParser(mockParser, someTimeout, 3, minDelay, maxDelay, clock)
+
+		gomock.InOrder(
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, unreachable),
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, badGateway),
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(someFeed, nil),
+		)
+
+		done := parse(context.Background(), retrying)
+
+		clock.BlockUntil(1)
+		clock.Advance(minDelay)
+		clock.BlockUntil(1)
+		clock.Advance(2 * minDelay)
+
+		res := <-done
+		require.NoError(t, res.err)
+		assert.Equal(t, someFeed, res.feed)
+	})
+	t.Run("should fetch a feed again after an attempt timed out", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := NewMockUniversalParser(ctrl)
+		clock := clockwork.NewFakeClock()
+		retrying := NewRetryingParser(mockParser, someTimeout, 3, minDelay, maxDelay, clock)
+
+		gomock.InOrder(
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).DoAndReturn(func(context.Context, string) (domain.Feed, error) {
+				// the attempt takes up a third of the call's timeout
+				clock.Advance(someTimeout / 3)
+				return domain.Feed{}, &domain.FetchError{Kind: domain.ErrFeedTimeout, Err: context.DeadlineExceeded}
+			}),
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(someFeed, nil),
+		)
+
+		done := parse(context.Background(), retrying)
+
+		clock.BlockUntil(1)
+		clock.Advance(minDelay)
+
+		res := <-done
+		require.NoError(t, res.err)
+		assert.Equal(t, someFeed, res.feed)
+	})
+	t.Run("should return the last failure once every attempt has been made", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := NewMockUniversalParser(ctrl)
+		clock := clockwork.NewFakeClock()
+		retrying := NewRetryingParser(mockParser, someTimeout, 2, minDelay, maxDelay, clock)
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, unreachable)
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, badGateway)
+
+		done := parse(context.Background(), retrying)
+
+		clock.BlockUntil(1)
+		clock.Advance(minDelay)
+
+		assert.ErrorIs(t, (<-done).err, domain.ErrUpstreamStatus)
+	})
+	t.Run("should not fetch a feed again after a failure that will not pass", func(t *testing.T) {
+		for _, err := range []error{
+			&domain.FetchError{Kind: domain.ErrUpstreamStatus, StatusCode: http.StatusNotFound},
+			&domain.FetchError{Kind: domain.ErrMalformedFeed},
+			&domain.FetchError{Kind: domain.ErrBlockedURL},
+			&domain.FetchError{Kind: domain.ErrCircuitOpen, RetryAfter: time.Second},
+			&domain.FetchError{Kind: domain.ErrHostThrottled},
+			ErrNotModified,
+			assert.AnError,
+		} {
+			ctrl := gomock.NewController(t)
+			mockParser := NewMockUniversalParser(ctrl)
+			retrying := NewRetryingParser(mockParser, someTimeout, 3, minDelay, maxDelay, clockwork.NewFakeClock())
+
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, err)
+
+			_, parseErr := retrying.Parse(context.Background(), someFeedURL)
+			assert.Equal(t, err, parseErr)
+			ctrl.Finish()
+		}
+	})
+	t.Run("should wait for as long as the host asks when it fits within the maximum delay", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := NewMockUniversalParser(ctrl)
+		clock := clockwork.NewFakeClock()
+		retrying := NewRetryingParser(mockParser, someTimeout, 3, minDelay, maxDelay, clock)
+
+		gomock.InOrder(
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, &domain.FetchError{Kind: domain.ErrHostThrottled, RetryAfter: 5 * time.Second}),
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, &domain.FetchError{Kind: domain.ErrUpstreamStatus, StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Minute}),
+		)
+
+		done := parse(context.Background(), retrying)
+
+		clock.BlockUntil(1)
+		clock.Advance(4 * time.Second)
+		select {
+		case <-done:
+			t.Fatal("the retry should wait for the host")
+		default:
+		}
+		clock.Advance(time.Second)
+
+		var fetchErr *domain.FetchError
+		require.ErrorAs(t, (<-done).err, &fetchErr)
+		assert.Equal(t, time.Minute, fetchErr.RetryAfter)
+	})
+	t.Run("should return the failure of the feed when its host holds back the retry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := NewMockUniversalParser(ctrl)
+		clock := clockwork.NewFakeClock()
+		retrying := NewRetryingParser(mockParser, someTimeout, 3, minDelay, maxDelay, clock)
+
+		gomock.InOrder(
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, badGateway),
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, &domain.FetchError{Kind: domain.ErrHostThrottled, RetryAfter: time.Minute}),
+		)
+
+		done := parse(context.Background(), retrying)
+
+		clock.BlockUntil(1)
+		clock.Advance(minDelay)
+
+		assert.Equal(t, badGateway, (<-done).err)
+	})
+	t.Run("should not fetch a feed again when the call would time out before the retry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := NewMockUniversalParser(ctrl)
+		clock := clockwork.NewFakeClock()
+		retrying := NewRetryingParser(mockParser, someTimeout, 3, minDelay, maxDelay, clock)
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).DoAndReturn(func(context.Context, string) (domain.Feed, error) {
+			clock.Advance(someTimeout - minDelay/4)
+			return domain.Feed{}, unreachable
+		})
+
+		_, err := retrying.Parse(context.Background(), someFeedURL)
+		assert.ErrorIs(t, err, domain.ErrFeedUnreachable)
+	})
+	t.Run("should not fetch a feed again once the timeout of the call is spent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := NewMockUniversalParser(ctrl)
+		retrying := NewRetryingParser(mockParser, 10*time.Millisecond, 3, minDelay, maxDelay, clockwork.NewFakeClock())
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).DoAndReturn(func(ctx context.Context, _ string) (domain.Feed, error) {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			<-ctx.Done()
+			return domain.Feed{}, &domain.FetchError{Kind: domain.ErrFeedTimeout, Err: ctx.Err()}
+		})
+
+		_, err := retrying.Parse(context.Background(), someFeedURL)
+		assert.ErrorIs(t, err, domain.ErrFeedTimeout)
+	})
+	t.Run("should stop waiting when the call is canceled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := NewMockUniversalParser(ctrl)
+		clock := clockwork.NewFakeClock()
+		retrying := NewRetryingParser(mockParser, someTimeout, 3, minDelay, maxDelay, clock)
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, unreachable)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := parse(ctx, retrying)
+
+		clock.BlockUntil(1)
+		cancel()
+
+		assert.ErrorIs(t, (<-done).err, domain.ErrFeedUnreachable)
+	})
+}
@@ -0,0 +1,204 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"news-app/internal/domain"
+	"news-app/internal/logging"
+	"news-app/internal/service"
+)
+
+// Scheduler is an interface for refreshing registered feeds in the background
+type Scheduler interface {
+	Start(ctx context.Context)
+	Stop()
+	Ping(ctx context.Context) error
+}
+
+// scheduler is the internal representation of our feed poller
+type scheduler struct {
+	service  service.Service
+	clock    clockwork.Clock
+	interval time.Duration
+	jitter   time.Duration
+	tick     time.Duration
+	workers  int
+
+	// nextRun and inFlight are only touched by the loop goroutine
+	nextRun  map[string]time.Time
+	inFlight map[string]bool
+
+	jobs   chan domain.Subscription
+	done   chan string
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// running is set while the loop goroutine is running, it is read by Ping
+	running int32
+}
+
+// NewScheduler is a constructor for a Scheduler
+// interval represents how often a feed is refreshed when it does not set its own interval
+// jitter represents the maximum random delay added to each refresh so feeds do not all refresh at once
+// tickerDuration represents how long between each check for feeds that are due
+// workers represents the maximum number of feeds refreshed at the same time
+func NewScheduler(service service.Service, interval, jitter, tickerDuration time.Duration, workers int, clock clockwork.Clock) Scheduler {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &scheduler{
+		service:  service,
+		clock:    clock,
+		interval: interval,
+		jitter:   jitter,
+		tick:     tickerDuration,
+		workers:  workers,
+		nextRun:  make(map[string]time.Time),
+		inFlight: make(map[string]bool),
+		jobs:     make(chan domain.Subscription, workers),
+		done:     make(chan string),
+	}
+}
+
+// Start begins polling registered feeds until ctx is cancelled or Stop is called
+func (s *scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for i := 0; i < s.workers; i++ {
+		s.wg.Add(1)
+		go s.work(ctx)
+	}
+
+	s.wg.Add(1)
+	atomic.StoreInt32(&s.running, 1)
+	go s.loop(ctx)
+}
+
+// Stop cancels any refreshes in progress and waits for every goroutine to exit
+func (s *scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// Ping reports whether the scheduler has been started and has not stopped
+func (s *scheduler) Ping(context.Context) error {
+	if atomic.LoadInt32(&s.running) == 0 {
+		return errors.New("scheduler is not running")
+	}
+
+	return nil
+}
+
+// loop evaluates which feeds are due every tick and hands them to the worker pool
+func (s *scheduler) loop(ctx context.Context) {
+	defer s.wg.Done()
+	defer atomic.StoreInt32(&s.running, 0)
+
+	ticker := s.clock.NewTicker(s.tick)
+	defer ticker.Stop()
+
+	s.dispatch(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.done:
+			delete(s.inFlight, id)
+		case <-ticker.Chan():
+			s.dispatch(ctx)
+		}
+	}
+}
+
+// dispatch queues every due feed for the worker pool. Feeds that are due while the queue is full are left until the next tick
+func (s *scheduler) dispatch(ctx context.Context) {
+	feeds, err := s.service.ListFeeds(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("scheduler failed to list feeds", "error", err)
+		return
+	}
+
+	now := s.clock.Now()
+	registered := make(map[string]bool, len(feeds))
+
+	for _, feed := range feeds {
+		registered[feed.ID] = true
+
+		if s.inFlight[feed.ID] {
+			continue
+		}
+
+		next, ok := s.nextRun[feed.ID]
+		if !ok {
+			// spread the first refresh of each feed across the jitter window
+			next = now.Add(s.randomJitter())
+			s.nextRun[feed.ID] = next
+		}
+		if now.Before(next) {
+			continue
+		}
+
+		select {
+		case s.jobs <- feed:
+			s.inFlight[feed.ID] = true
+			s.nextRun[feed.ID] = now.Add(s.intervalFor(feed) + s.randomJitter())
+		default:
+			// every worker is busy and the queue is full, try again next tick
+		}
+	}
+
+	for id := range s.nextRun {
+		if !registered[id] {
+			delete(s.nextRun, id)
+		}
+	}
+}
+
+// work refreshes feeds handed to it by the loop until ctx is cancelled
+func (s *scheduler) work(ctx context.Context) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case feed := <-s.jobs:
+			logger := logging.FromContext(ctx).With("feed_id", feed.ID, "feed_url", feed.URL)
+			if _, err := s.service.RefreshArticles(logging.NewContext(ctx, logger), feed.URL); err != nil {
+				logger.Error("scheduler failed to refresh feed", "error", err)
+			}
+
+			select {
+			case s.done <- feed.ID:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (s *scheduler) intervalFor(feed domain.Subscription) time.Duration {
+	if feed.RefreshIntervalSeconds > 0 {
+		return time.Duration(feed.RefreshIntervalSeconds) * time.Second
+	}
+
+	return s.interval
+}
+
+func (s *scheduler) randomJitter() time.Duration {
+	if s.jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(s.jitter)))
+}
@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+	"news-app/internal/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_scheduler(t *testing.T) {
+	const (
+		someURL      = "some-url"
+		someOtherURL = "some-other-url"
+	)
+	var (
+		someFeed = domain.Subscription{
+			ID:                     "some-id",
+			URL:                    someURL,
+			RefreshIntervalSeconds: 10,
+		}
+		someOtherFeed = domain.Subscription{
+			ID:  "some-other-id",
+			URL: someOtherURL,
+		}
+		someInterval       = time.Minute
+		someTickerDuration = time.Second
+	)
+
+	// waitFor fails the test if nothing is received on c within a second
+	waitFor := func(t *testing.T, c <-chan string) string {
+		t.Helper()
+		select {
+		case url := <-c:
+			return url
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for refresh")
+			return ""
+		}
+	}
+
+	t.Run("should refresh every registered feed when started", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		clock := clockwork.NewFakeClock()
+		scheduler := NewScheduler(mockService, someInterval, 0, someTickerDuration, 2, clock)
+
+		refreshed := make(chan string, 2)
+		mockService.EXPECT().ListFeeds(gomock.Any()).Return([]domain.Subscription{someFeed, someOtherFeed}, nil).AnyTimes()
+		mockService.EXPECT().RefreshArticles(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, url string) ([]domain.Article, error) {
+			refreshed <- url
+			return nil, nil
+		}).Times(2)
+
+		scheduler.Start(context.Background())
+		defer scheduler.Stop()
+
+		urls := []string{waitFor(t, refreshed), waitFor(t, refreshed)}
+		assert.ElementsMatch(t, []string{someURL, someOtherURL}, urls)
+	})
+
+	t.Run("should refresh a feed again once its interval has passed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		clock := clockwork.NewFakeClock()
+		scheduler := NewScheduler(mockService, someInterval, 0, someTickerDuration, 1, clock)
+
+		refreshed := make(chan string, 2)
+		mockService.EXPECT().ListFeeds(gomock.Any()).Return([]domain.Subscription{someFeed}, nil).AnyTimes()
+		mockService.EXPECT().RefreshArticles(gomock.Any(), someURL).DoAndReturn(func(_ context.Context, url string) ([]domain.Article, error) {
+			refreshed <- url
+			return nil, nil
+		}).Times(2)
+
+		scheduler.Start(context.Background())
+		defer scheduler.Stop()
+
+		waitFor(t, refreshed)
+
+		// advance a tick at a time until the feed's own interval has passed
+		for i := 0; i < someFeed.RefreshIntervalSeconds; i++ {
+			select {
+			case <-refreshed:
+				t.Fatal("feed refreshed before its interval passed")
+			default:
+			}
+			clock.BlockUntil(1)
+			clock.Advance(someTickerDuration)
+		}
+
+		// the loop may not have seen the first refresh finish by the time the interval passed
+		deadline := time.After(time.Second)
+		for {
+			select {
+			case <-refreshed:
+				return
+			case <-deadline:
+				t.Fatal("timed out waiting for refresh")
+			case <-time.After(10 * time.Millisecond):
+				clock.BlockUntil(1)
+				clock.Advance(someTickerDuration)
+			}
+		}
+	})
+
+	t.Run("should not refresh more feeds at once than there are workers", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		clock := clockwork.NewFakeClock()
+		scheduler := NewScheduler(mockService, someInterval, 0, someTickerDuration, 1, clock)
+
+		var active, maxActive int32
+		refreshed := make(chan string, 2)
+		mockService.EXPECT().ListFeeds(gomock.Any()).Return([]domain.Subscription{someFeed, someOtherFeed}, nil).AnyTimes()
+		mockService.EXPECT().RefreshArticles(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, url string) ([]domain.Article, error) {
+			n := atomic.AddInt32(&active, 1)
+			if n > atomic.LoadInt32(&maxActive) {
+				atomic.StoreInt32(&maxActive, n)
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+
+			refreshed <- url
+			return nil, nil
+		}).Times(2)
+
+		scheduler.Start(context.Background())
+		defer scheduler.Stop()
+
+		waitFor(t, refreshed)
+
+		// the second feed did not fit in the queue on start so is picked up on a later tick
+		clock.BlockUntil(1)
+		clock.Advance(someTickerDuration)
+
+		waitFor(t, refreshed)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&maxActive))
+	})
+
+	t.Run("should only report running between start and stop", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		scheduler := NewScheduler(mockService, someInterval, 0, someTickerDuration, 1, clockwork.NewFakeClock())
+		mockService.EXPECT().ListFeeds(gomock.Any()).Return(nil, nil).AnyTimes()
+
+		assert.Error(t, scheduler.Ping(context.Background()))
+
+		scheduler.Start(context.Background())
+		assert.NoError(t, scheduler.Ping(context.Background()))
+
+		scheduler.Stop()
+		assert.Error(t, scheduler.Ping(context.Background()))
+	})
+}
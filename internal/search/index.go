@@ -0,0 +1,398 @@
+//go:generate mockgen -package=search -destination=./search_mock.go . Index
+
+package search
+
+import (
+	"encoding/base64"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"news-app/internal/domain"
+)
+
+// Index is an interface for full text search over articles
+type Index interface {
+	Add(feedURL string, articles []domain.Article)
+	Move(from, to string)
+	Search(query domain.SearchQuery) (domain.SearchResults, error)
+}
+
+// field is a part of an article that is indexed
+type field int
+
+const (
+	titleField field = iota
+	descriptionField
+	contentField
+	fieldCount
+)
+
+// fieldWeights make a match in the title count for more than a match in the body
+var fieldWeights = [fieldCount]float64{3, 1.5, 1}
+
+const (
+	// bm25K1 controls how quickly repeated terms stop adding to the score
+	bm25K1 = 1.2
+	// bm25B controls how much long articles are penalised
+	bm25B = 0.75
+)
+
+// document is an indexed article
+type document struct {
+	feedURL string
+	article domain.Article
+	// terms are the distinct terms in the article so its postings can be removed when it is re-indexed
+	terms []string
+	// length is the weighted number of tokens in the article
+	length float64
+}
+
+// posting records where a term appears within a single document
+type posting struct {
+	positions [fieldCount][]int
+}
+
+// index is the internal representation of an in memory inverted index
+type index struct {
+	mutex       sync.RWMutex
+	ids         map[string]int
+	docs        map[int]*document
+	postings    map[string]map[int]*posting
+	nextID      int
+	totalLength float64
+}
+
+// NewIndex is a constructor for an empty Index
+func NewIndex() Index {
+	return &index{
+		ids:      make(map[string]int),
+		docs:     make(map[int]*document),
+		postings: make(map[string]map[int]*posting),
+	}
+}
+
+// Add indexes articles from a feed, articles that have already been indexed are replaced.
+// Articles without an ID are skipped as they cannot be recognised when they are added again.
+func (i *index) Add(feedURL string, articles []domain.Article) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, article := range articles {
+		if article.ID == "" {
+			continue
+		}
+
+		key := feedURL + "\x00" + article.ID
+		id, ok := i.ids[key]
+		if ok {
+			i.remove(id)
+		} else {
+			id = i.nextID
+			i.nextID++
+			i.ids[key] = id
+		}
+
+		article.Source = nil
+		i.add(id, feedURL, article)
+	}
+}
+
+// Move indexes the articles of a feed under the URL it moved to, articles already indexed under that URL are kept instead
+func (i *index) Move(from, to string) {
+	if from == to {
+		return
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	prefix := from + "\x00"
+	for key, id := range i.ids {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		delete(i.ids, key)
+
+		moved := to + "\x00" + key[len(prefix):]
+		if _, ok := i.ids[moved]; ok {
+			i.remove(id)
+			continue
+		}
+		i.ids[moved] = id
+		i.docs[id].feedURL = to
+	}
+}
+
+func (i *index) add(id int, feedURL string, article domain.Article) {
+	doc := &document{
+		feedURL: feedURL,
+		article: article,
+	}
+
+	texts := [fieldCount]string{article.Title, article.Description, article.Content}
+	for f, text := range texts {
+		tokens := tokenize(text)
+		doc.length += fieldWeights[f] * float64(len(tokens))
+
+		for _, t := range tokens {
+			postings, ok := i.postings[t.term]
+			if !ok {
+				postings = make(map[int]*posting)
+				i.postings[t.term] = postings
+			}
+
+			p, ok := postings[id]
+			if !ok {
+				p = &posting{}
+				postings[id] = p
+				doc.terms = append(doc.terms, t.term)
+			}
+			p.positions[f] = append(p.positions[f], t.position)
+		}
+	}
+
+	i.docs[id] = doc
+	i.totalLength += doc.length
+}
+
+func (i *index) remove(id int) {
+	doc := i.docs[id]
+	for _, term := range doc.terms {
+		delete(i.postings[term], id)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+
+	i.totalLength -= doc.length
+	delete(i.docs, id)
+}
+
+// clause is a single term or a phrase from a query, every clause must match for an article to be returned
+type clause []token
+
+// parseQuery splits a query into clauses, text inside double quotes becomes a phrase and every other word is its own clause
+func parseQuery(query string) []clause {
+	var clauses []clause
+
+	for n, part := range strings.Split(query, `"`) {
+		// odd parts were inside quotes
+		if n%2 == 1 {
+			if tokens := tokenize(part); len(tokens) > 0 {
+				clauses = append(clauses, tokens)
+			}
+			continue
+		}
+
+		for _, t := range tokenize(part) {
+			clauses = append(clauses, clause{t})
+		}
+	}
+
+	return clauses
+}
+
+// Search returns articles matching every clause of the query ordered by relevance
+func (i *index) Search(query domain.SearchQuery) (domain.SearchResults, error) {
+	clauses := parseQuery(query.Query)
+	if len(clauses) == 0 {
+		return domain.SearchResults{}, domain.ErrInvalidQuery
+	}
+
+	offset, err := decodeOffset(query.Page.Cursor)
+	if err != nil {
+		return domain.SearchResults{}, err
+	}
+
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	feeds := make(map[string]bool, len(query.Feeds))
+	for _, feed := range query.Feeds {
+		feeds[feed] = true
+	}
+
+	var results []domain.SearchResult
+	for _, id := range i.candidates(clauses) {
+		doc := i.docs[id]
+
+		if len(feeds) > 0 && !feeds[doc.feedURL] {
+			continue
+		}
+		if !query.From.IsZero() && doc.article.Published.Before(query.From) {
+			continue
+		}
+		if !query.To.IsZero() && doc.article.Published.After(query.To) {
+			continue
+		}
+		if !i.matchesPhrases(id, clauses) {
+			continue
+		}
+
+		article := doc.article
+		article.Source = &domain.Source{URL: doc.feedURL}
+		results = append(results, domain.SearchResult{
+			Article: article,
+			Score:   i.score(id, clauses),
+		})
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		if !results[a].Published.Equal(results[b].Published) {
+			return results[a].Published.After(results[b].Published)
+		}
+		return results[a].ID < results[b].ID
+	})
+
+	return paginate(results, offset, query.Page.Limit), nil
+}
+
+// candidates returns the documents that contain every term in the query, starting from the rarest term
+func (i *index) candidates(clauses []clause) []int {
+	var terms []string
+	for _, c := range clauses {
+		for _, t := range c {
+			terms = append(terms, t.term)
+		}
+	}
+
+	sort.Slice(terms, func(a, b int) bool {
+		return len(i.postings[terms[a]]) < len(i.postings[terms[b]])
+	})
+
+	var ids []int
+	for id := range i.postings[terms[0]] {
+		ids = append(ids, id)
+	}
+
+	for _, term := range terms[1:] {
+		postings := i.postings[term]
+		matched := ids[:0]
+		for _, id := range ids {
+			if _, ok := postings[id]; ok {
+				matched = append(matched, id)
+			}
+		}
+		ids = matched
+	}
+
+	return ids
+}
+
+// matchesPhrases reports whether every multi word clause appears in order within a single field of the document
+func (i *index) matchesPhrases(id int, clauses []clause) bool {
+	for _, c := range clauses {
+		if len(c) > 1 && !i.matchesPhrase(id, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (i *index) matchesPhrase(id int, phrase clause) bool {
+	first := i.postings[phrase[0].term][id]
+
+	for f := field(0); f < fieldCount; f++ {
+	positions:
+		for _, start := range first.positions[f] {
+			for _, t := range phrase[1:] {
+				want := start + t.position - phrase[0].position
+				if !containsInt(i.postings[t.term][id].positions[f], want) {
+					continue positions
+				}
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+// score ranks a document with BM25, term frequencies are weighted by the field they appear in
+func (i *index) score(id int, clauses []clause) float64 {
+	doc := i.docs[id]
+	n := float64(len(i.docs))
+	avgLength := i.totalLength / n
+
+	var score float64
+	for _, c := range clauses {
+		for _, t := range c {
+			postings := i.postings[t.term]
+			df := float64(len(postings))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+			var tf float64
+			for f, positions := range postings[id].positions {
+				tf += fieldWeights[f] * float64(len(positions))
+			}
+
+			norm := 1 - bm25B
+			if avgLength > 0 {
+				norm += bm25B * doc.length / avgLength
+			}
+
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+
+	return score
+}
+
+func paginate(results []domain.SearchResult, offset, limit int) domain.SearchResults {
+	page := domain.SearchResults{
+		Results: []domain.SearchResult{},
+		Total:   len(results),
+	}
+	if offset >= len(results) {
+		return page
+	}
+
+	end := len(results)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+		page.NextCursor = encodeOffset(end)
+	}
+	page.Results = results[offset:end]
+
+	return page
+}
+
+// search results are ranked rather than ordered by date so cursors are an offset into the results
+func encodeOffset(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeOffset(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, domain.ErrInvalidCursor
+	}
+
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), "offset:"))
+	if err != nil || offset < 0 || !strings.HasPrefix(string(raw), "offset:") {
+		return 0, domain.ErrInvalidCursor
+	}
+
+	return offset, nil
+}
+
+func containsInt(values []int, want int) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,143 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_index_Search(t *testing.T) {
+	const (
+		someFeedURL  = "https://some-feed-url"
+		someOtherURL = "https://some-other-url"
+	)
+	var (
+		someTime        = time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)
+		someTitleMatch  = domain.Article{ID: "title", Title: "Energy prices soar", Published: someTime}
+		someBodyMatch   = domain.Article{ID: "body", Title: "Winter outlook", Content: "<p>Households worry about <b>energy</b> and food prices.</p>", Published: someTime.Add(-time.Hour)}
+		someOtherMatch  = domain.Article{ID: "other", Title: "Pricing energy for industry", Published: someTime.Add(-48 * time.Hour)}
+		someNonMatch    = domain.Article{ID: "none", Title: "Football results", Published: someTime}
+		someIndexedFeed = []domain.Article{someTitleMatch, someBodyMatch, someNonMatch}
+	)
+
+	ids := func(results domain.SearchResults) []string {
+		var ids []string
+		for _, r := range results.Results {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	setup := func() Index {
+		index := NewIndex()
+		index.Add(someFeedURL, someIndexedFeed)
+		index.Add(someOtherURL, []domain.Article{someOtherMatch})
+		return index
+	}
+
+	t.Run("should return articles matching every term ranked by relevance", func(t *testing.T) {
+		results, err := setup().Search(domain.SearchQuery{Query: "energy prices"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"title", "other", "body"}, ids(results))
+		assert.Equal(t, 3, results.Total)
+		assert.Equal(t, &domain.Source{URL: someFeedURL}, results.Results[0].Source)
+	})
+	t.Run("should only match phrases when the words appear together", func(t *testing.T) {
+		results, err := setup().Search(domain.SearchQuery{Query: `"energy prices"`})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"title"}, ids(results))
+	})
+	t.Run("should filter by feed and date range", func(t *testing.T) {
+		results, err := setup().Search(domain.SearchQuery{Query: "energy", Feeds: []string{someOtherURL}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"other"}, ids(results))
+
+		results, err = setup().Search(domain.SearchQuery{Query: "energy", From: someTime.Add(-2 * time.Hour), To: someTime.Add(-time.Minute)})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"body"}, ids(results))
+	})
+	t.Run("should replace articles that are added again", func(t *testing.T) {
+		index := setup()
+
+		updated := someTitleMatch
+		updated.Title = "Football scores"
+		index.Add(someFeedURL, []domain.Article{updated})
+
+		results, err := index.Search(domain.SearchQuery{Query: `"energy prices"`})
+		require.NoError(t, err)
+		assert.Empty(t, results.Results)
+
+		results, err = index.Search(domain.SearchQuery{Query: "football"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"title", "none"}, ids(results))
+	})
+	t.Run("should page through results using the next cursor", func(t *testing.T) {
+		index := setup()
+
+		first, err := index.Search(domain.SearchQuery{Query: "energy", Page: domain.PageRequest{Limit: 2}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"title", "other"}, ids(first))
+		require.NotEmpty(t, first.NextCursor)
+
+		second, err := index.Search(domain.SearchQuery{Query: "energy", Page: domain.PageRequest{Limit: 2, Cursor: first.NextCursor}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"body"}, ids(second))
+		assert.Empty(t, second.NextCursor)
+	})
+	t.Run("should return an error if the query has no searchable terms", func(t *testing.T) {
+		_, err := setup().Search(domain.SearchQuery{Query: "the and"})
+		assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+	})
+	t.Run("should return an error for an invalid cursor", func(t *testing.T) {
+		_, err := setup().Search(domain.SearchQuery{Query: "energy", Page: domain.PageRequest{Cursor: "!"}})
+		assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+	})
+}
+
+func Test_index_Move(t *testing.T) {
+	const (
+		someFeedURL  = "https://some-feed-url"
+		someNewURL   = "https://some-new-url"
+		someOtherURL = "https://some-other-url"
+	)
+	var (
+		someArticle      = domain.Article{ID: "some-id", Title: "Energy prices soar"}
+		someOtherArticle = domain.Article{ID: "some-other-id", Title: "Energy bills rise"}
+	)
+
+	t.Run("should index the articles of a feed under the url it moved to", func(t *testing.T) {
+		index := NewIndex()
+		index.Add(someFeedURL, []domain.Article{someArticle, someOtherArticle})
+		index.Add(someNewURL, []domain.Article{someOtherArticle})
+		index.Add(someOtherURL, []domain.Article{someArticle})
+
+		index.Move(someFeedURL, someNewURL)
+
+		results, err := index.Search(domain.SearchQuery{Query: "energy", Feeds: []string{someNewURL}})
+		require.NoError(t, err)
+		assert.Equal(t, 2, results.Total)
+		for _, result := range results.Results {
+			assert.Equal(t, &domain.Source{URL: someNewURL}, result.Source)
+		}
+
+		results, err = index.Search(domain.SearchQuery{Query: "energy", Feeds: []string{someFeedURL}})
+		require.NoError(t, err)
+		assert.Empty(t, results.Results)
+
+		results, err = index.Search(domain.SearchQuery{Query: "energy"})
+		require.NoError(t, err)
+		assert.Equal(t, 3, results.Total)
+
+		// articles moved once are replaced when added again under the new url
+		index.Add(someNewURL, []domain.Article{someArticle})
+		results, err = index.Search(domain.SearchQuery{Query: "energy"})
+		require.NoError(t, err)
+		assert.Equal(t, 3, results.Total)
+	})
+}
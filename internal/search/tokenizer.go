@@ -0,0 +1,49 @@
+package search
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// token is a stemmed term and its position in the text it was read from
+type token struct {
+	term     string
+	position int
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// stopWords are too common to be useful in a query so are not indexed
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "for": true, "from": true, "has": true, "have": true, "he": true, "her": true, "his": true,
+	"if": true, "in": true, "into": true, "is": true, "it": true, "its": true, "of": true, "on": true,
+	"or": true, "she": true, "that": true, "the": true, "their": true, "there": true, "they": true,
+	"this": true, "to": true, "was": true, "were": true, "will": true, "with": true,
+}
+
+// tokenize splits text into lower case stemmed terms, HTML tags are removed and stop words are dropped.
+// Positions count every word including stop words so phrase queries match the original spacing.
+func tokenize(text string) []token {
+	text = html.UnescapeString(htmlTag.ReplaceAllString(text, " "))
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]token, 0, len(words))
+	for i, word := range words {
+		if stopWords[word] {
+			continue
+		}
+
+		tokens = append(tokens, token{
+			term:     stem(word),
+			position: i,
+		})
+	}
+
+	return tokens
+}
@@ -0,0 +1,28 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_tokenize(t *testing.T) {
+	t.Run("should lower case, stem and drop stop words", func(t *testing.T) {
+		tokens := tokenize("The Energy Prices are Rising")
+		assert.Equal(t, []token{
+			{term: "energi", position: 1},
+			{term: "price", position: 2},
+			{term: "rise", position: 4},
+		}, tokens)
+	})
+	t.Run("should strip html and decode entities", func(t *testing.T) {
+		tokens := tokenize(`<p>Fish &amp; <a href="https://chips.com">chips</a></p>`)
+		assert.Equal(t, []token{
+			{term: "fish", position: 0},
+			{term: "chip", position: 1},
+		}, tokens)
+	})
+	t.Run("should return no tokens for empty text", func(t *testing.T) {
+		assert.Empty(t, tokenize(""))
+	})
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"news-app/internal/domain"
+)
+
+// ListCacheEntries describes every feed currently held in the cache ordered by URL
+func (s service) ListCacheEntries(ctx context.Context) ([]domain.CacheEntry, error) {
+	entries := []domain.CacheEntry{}
+	for _, key := range s.cache.Keys() {
+		// the entry may have been evicted since the keys were listed
+		if entry, ok := s.cache.GetEntry(key); ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].URL < entries[j].URL
+	})
+
+	return entries, nil
+}
+
+// InvalidateFeed removes a feed from the cache so the next request parses it again.
+// The feed may be either the ID of a registered feed or a feed URL.
+func (s service) InvalidateFeed(ctx context.Context, feed string) error {
+	feedURL, err := s.resolveFeedURL(feed)
+	if err != nil {
+		return err
+	}
+
+	if !s.cache.DeleteArticlesFromCache(feedURL) {
+		return fmt.Errorf("failed to invalidate feed %s: %w", feed, domain.ErrNotCached)
+	}
+
+	return nil
+}
+
+// RefreshFeed parses a registered feed immediately rather than waiting for the poller
+func (s service) RefreshFeed(ctx context.Context, id string) error {
+	feed, err := s.feedStore.GetFeed(id)
+	if err != nil {
+		return fmt.Errorf("failed to get feed: %w", err)
+	}
+
+	if _, err := s.RefreshArticles(ctx, feed.URL); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// resolveFeedURL returns the URL of a registered feed ID, or the source itself if it is a URL
+func (s service) resolveFeedURL(source string) (string, error) {
+	feed, err := s.feedStore.GetFeed(source)
+	switch {
+	case err == nil:
+		return feed.URL, nil
+	case errors.Is(err, domain.ErrFeedNotFound) && isURL(source):
+		return source, nil
+	default:
+		return "", fmt.Errorf("failed to get feed: %w", err)
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"news-app/internal/cache"
+	"news-app/internal/domain"
+	"news-app/internal/parser"
+	"news-app/internal/search"
+	"news-app/internal/store"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_service_Admin(t *testing.T) {
+	const (
+		someID       = "some-id"
+		someFeedURL  = "https://some-feed-url"
+		someOtherURL = "https://some-other-url"
+	)
+	var (
+		someFeed       = domain.Subscription{ID: someID, URL: someFeedURL}
+		someEntry      = domain.CacheEntry{URL: someFeedURL, Created: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Size: 10}
+		someOtherEntry = domain.CacheEntry{URL: someOtherURL, Created: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), Size: 20}
+	)
+
+	setup := func(t *testing.T) (Service, *parser.MockUniversalParser, *cache.MockCache, *store.MockFeedStore) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockStore := store.NewMockFeedStore(ctrl)
+		service := NewService(mockParser, mockCache, mockStore, store.NewMockArticleStore(ctrl), search.NewMockIndex(ctrl))
+
+		return service, mockParser, mockCache, mockStore
+	}
+
+	t.Run("should list cache entries ordered by url skipping entries evicted while listing", func(t *testing.T) {
+		service, _, mockCache, _ := setup(t)
+
+		mockCache.EXPECT().Keys().Return([]string{someOtherURL, "https://some-evicted-url", someFeedURL})
+		mockCache.EXPECT().GetEntry(someOtherURL).Return(someOtherEntry, true)
+		mockCache.EXPECT().GetEntry("https://some-evicted-url").Return(domain.CacheEntry{}, false)
+		mockCache.EXPECT().GetEntry(someFeedURL).Return(someEntry, true)
+
+		entries, err := service.ListCacheEntries(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []domain.CacheEntry{someEntry, someOtherEntry}, entries)
+	})
+	t.Run("should invalidate a feed by id or url", func(t *testing.T) {
+		service, _, mockCache, mockStore := setup(t)
+
+		mockStore.EXPECT().GetFeed(someID).Return(someFeed, nil)
+		mockStore.EXPECT().GetFeed(someOtherURL).Return(domain.Subscription{}, domain.ErrFeedNotFound)
+		mockCache.EXPECT().DeleteArticlesFromCache(someFeedURL).Return(true)
+		mockCache.EXPECT().DeleteArticlesFromCache(someOtherURL).Return(true)
+
+		assert.NoError(t, service.InvalidateFeed(context.Background(), someID))
+		assert.NoError(t, service.InvalidateFeed(context.Background(), someOtherURL))
+	})
+	t.Run("should return an error when invalidating a feed that is not cached", func(t *testing.T) {
+		service, _, mockCache, mockStore := setup(t)
+
+		mockStore.EXPECT().GetFeed(someID).Return(someFeed, nil)
+		mockCache.EXPECT().DeleteArticlesFromCache(someFeedURL).Return(false)
+
+		err := service.InvalidateFeed(context.Background(), someID)
+		assert.ErrorIs(t, err, domain.ErrNotCached)
+	})
+	t.Run("should return an error when invalidating something that is neither a feed nor a url", func(t *testing.T) {
+		service, _, _, mockStore := setup(t)
+
+		mockStore.EXPECT().GetFeed("some-unknown-id").Return(domain.Subscription{}, domain.ErrFeedNotFound)
+
+		err := service.InvalidateFeed(context.Background(), "some-unknown-id")
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+	})
+	t.Run("should refresh a registered feed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockStore := store.NewMockFeedStore(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, mockStore, mockArticleStore, mockIndex)
+
+		someArticles := []domain.Article{{ID: "some-article-id"}}
+
+		mockStore.EXPECT().GetFeed(someID).Return(someFeed, nil)
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{Articles: someArticles}, nil)
+		mockIndex.EXPECT().Add(someFeedURL, someArticles)
+		mockArticleStore.EXPECT().UpsertArticles(someFeedURL, someArticles).Return(nil)
+		mockArticleStore.EXPECT().GetArticles(someFeedURL).Return(someArticles, nil)
+		mockCache.EXPECT().AddArticlesToCache(someFeedURL, someArticles)
+
+		assert.NoError(t, service.RefreshFeed(context.Background(), someID))
+	})
+	t.Run("should return an error if refreshing a registered feed fails", func(t *testing.T) {
+		service, mockParser, _, mockStore := setup(t)
+
+		mockStore.EXPECT().GetFeed(someID).Return(someFeed, nil)
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, assert.AnError)
+
+		err := service.RefreshFeed(context.Background(), someID)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+	t.Run("should return an error when refreshing a feed that is not registered", func(t *testing.T) {
+		service, _, _, mockStore := setup(t)
+
+		mockStore.EXPECT().GetFeed(someID).Return(domain.Subscription{}, domain.ErrFeedNotFound)
+
+		err := service.RefreshFeed(context.Background(), someID)
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+	})
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"news-app/internal/domain"
+	"news-app/internal/logging"
+)
+
+// ListFeeds returns every registered feed subscription
+func (s service) ListFeeds(ctx context.Context) ([]domain.Subscription, error) {
+	feeds, err := s.feedStore.ListFeeds()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+
+	return feeds, nil
+}
+
+// GetFeed returns a single feed subscription by ID
+func (s service) GetFeed(ctx context.Context, id string) (domain.Subscription, error) {
+	feed, err := s.feedStore.GetFeed(id)
+	if err != nil {
+		return domain.Subscription{}, fmt.Errorf("failed to get feed: %w", err)
+	}
+
+	return feed, nil
+}
+
+// AddFeed registers a new feed subscription, the returned subscription contains its assigned ID
+func (s service) AddFeed(ctx context.Context, feed domain.Subscription) (domain.Subscription, error) {
+	feed, err := s.feedStore.AddFeed(feed)
+	if err != nil {
+		return domain.Subscription{}, fmt.Errorf("failed to add feed: %w", err)
+	}
+
+	return feed, nil
+}
+
+// UpdateFeed applies the non nil fields of update to the feed subscription with the given ID
+func (s service) UpdateFeed(ctx context.Context, id string, update domain.SubscriptionUpdate) (domain.Subscription, error) {
+	feed, err := s.feedStore.GetFeed(id)
+	if err != nil {
+		return domain.Subscription{}, fmt.Errorf("failed to get feed: %w", err)
+	}
+
+	if update.URL != nil {
+		feed.URL = *update.URL
+	}
+	if update.Title != nil {
+		feed.Title = *update.Title
+	}
+	if update.Category != nil {
+		feed.Category = *update.Category
+	}
+	if update.RefreshIntervalSeconds != nil {
+		feed.RefreshIntervalSeconds = *update.RefreshIntervalSeconds
+	}
+
+	feed, err = s.feedStore.UpdateFeed(feed)
+	if err != nil {
+		return domain.Subscription{}, fmt.Errorf("failed to update feed: %w", err)
+	}
+
+	return feed, nil
+}
+
+// RemoveFeed deletes the feed subscription with the given ID
+func (s service) RemoveFeed(ctx context.Context, id string) error {
+	if err := s.feedStore.RemoveFeed(id); err != nil {
+		return fmt.Errorf("failed to remove feed: %w", err)
+	}
+
+	return nil
+}
+
+// moveFeed points subscriptions to a feed the publisher permanently redirected at its new URL and moves the feed's stored
+// articles and search index entries with them, the validators kept under the old URL are dropped.
+// Failures are only logged since the feed was still fetched, the subscriptions are moved on a later refresh.
+func (s service) moveFeed(ctx context.Context, from, to string) {
+	logger := logging.FromContext(ctx)
+
+	if err := s.articleStore.MoveArticles(from, to); err != nil {
+		logger.Warn("failed to move stored articles", "feed_url", from, "moved_to", to, "error", err)
+	}
+	s.index.Move(from, to)
+	// the articles a 304 for the old URL would stand for are no longer stored under it
+	s.parser.Forget(from)
+
+	feeds, err := s.feedStore.ListFeeds()
+	if err != nil {
+		logger.Warn("failed to move feed", "feed_url", from, "moved_to", to, "error", err)
+		return
+	}
+
+	for _, feed := range feeds {
+		if feed.URL != from {
+			continue
+		}
+
+		feed.URL = to
+		if _, err := s.feedStore.UpdateFeed(feed); err != nil {
+			logger.Warn("failed to move feed", "feed_id", feed.ID, "feed_url", from, "moved_to", to, "error", err)
+			continue
+		}
+		logger.Info("moved feed", "feed_id", feed.ID, "feed_url", from, "moved_to", to)
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"news-app/internal/cache"
+	"news-app/internal/domain"
+	"news-app/internal/parser"
+	"news-app/internal/search"
+	"news-app/internal/store"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_service_Feeds(t *testing.T) {
+	const (
+		someID       = "some-id"
+		someFeedURL  = "some-feed-url"
+		someOtherURL = "some-other-url"
+		someTitle    = "some-title"
+		someCategory = "some-category"
+	)
+	var (
+		someFeed = domain.Subscription{
+			ID:       someID,
+			URL:      someFeedURL,
+			Title:    someTitle,
+			Category: someCategory,
+		}
+		someFeeds = []domain.Subscription{someFeed}
+	)
+
+	setup := func(t *testing.T) (Service, *store.MockFeedStore) {
+		ctrl := gomock.NewController(t)
+		mockStore := store.NewMockFeedStore(ctrl)
+		service := NewService(parser.NewMockUniversalParser(ctrl), cache.NewMockCache(ctrl), mockStore, store.NewMockArticleStore(ctrl), search.NewMockIndex(ctrl))
+
+		return service, mockStore
+	}
+
+	t.Run("should list feeds", func(t *testing.T) {
+		service, mockStore := setup(t)
+
+		mockStore.EXPECT().ListFeeds().Return(someFeeds, nil)
+
+		feeds, err := service.ListFeeds(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, someFeeds, feeds)
+	})
+	t.Run("should return an error if we fail to list feeds", func(t *testing.T) {
+		service, mockStore := setup(t)
+
+		mockStore.EXPECT().ListFeeds().Return(nil, assert.AnError)
+
+		feeds, err := service.ListFeeds(context.Background())
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Empty(t, feeds)
+	})
+	t.Run("should get a feed", func(t *testing.T) {
+		service, mockStore := setup(t)
+
+		mockStore.EXPECT().GetFeed(someID).Return(someFeed, nil)
+
+		feed, err := service.GetFeed(context.Background(), someID)
+		assert.NoError(t, err)
+		assert.Equal(t, someFeed, feed)
+	})
+	t.Run("should add a feed", func(t *testing.T) {
+		service, mockStore := setup(t)
+
+		newFeed := someFeed
+		newFeed.ID = ""
+		mockStore.EXPECT().AddFeed(newFeed).Return(someFeed, nil)
+
+		feed, err := service.AddFeed(context.Background(), newFeed)
+		assert.NoError(t, err)
+		assert.Equal(t, someFeed, feed)
+	})
+	t.Run("should return an error if feed already exists", func(t *testing.T) {
+		service, mockStore := setup(t)
+
+		mockStore.EXPECT().AddFeed(someFeed).Return(domain.Subscription{}, domain.ErrFeedAlreadyExists)
+
+		_, err := service.AddFeed(context.Background(), someFeed)
+		assert.ErrorIs(t, err, domain.ErrFeedAlreadyExists)
+	})
+	t.Run("should only update fields that are set", func(t *testing.T) {
+		service, mockStore := setup(t)
+
+		url := someOtherURL
+		updated := someFeed
+		updated.URL = someOtherURL
+
+		mockStore.EXPECT().GetFeed(someID).Return(someFeed, nil)
+		mockStore.EXPECT().UpdateFeed(updated).Return(updated, nil)
+
+		feed, err := service.UpdateFeed(context.Background(), someID, domain.SubscriptionUpdate{URL: &url})
+		assert.NoError(t, err)
+		assert.Equal(t, updated, feed)
+	})
+	t.Run("should return an error when updating a feed that does not exist", func(t *testing.T) {
+		service, mockStore := setup(t)
+
+		mockStore.EXPECT().GetFeed(someID).Return(domain.Subscription{}, domain.ErrFeedNotFound)
+
+		_, err := service.UpdateFeed(context.Background(), someID, domain.SubscriptionUpdate{})
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+	})
+	t.Run("should remove a feed", func(t *testing.T) {
+		service, mockStore := setup(t)
+
+		mockStore.EXPECT().RemoveFeed(someID).Return(nil)
+
+		err := service.RemoveFeed(context.Background(), someID)
+		assert.NoError(t, err)
+	})
+}
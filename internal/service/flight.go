@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"news-app/internal/domain"
+)
+
+// flightGroup de-duplicates concurrent fetches of the same feed so a popular feed expiring from the cache
+// results in a single request to the publisher rather than one per waiting client
+type flightGroup struct {
+	// running counts calls in progress so they can be waited for
+	running *sync.WaitGroup
+
+	mutex sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is a fetch in progress, waiters block on done and then share its result
+type flight struct {
+	done     chan struct{}
+	articles []domain.Article
+	err      error
+}
+
+func newFlightGroup(running *sync.WaitGroup) *flightGroup {
+	return &flightGroup{
+		running: running,
+		calls:   make(map[string]*flight),
+	}
+}
+
+// do calls fn for key unless a call for key is already in flight, then waits for that call's result or for ctx to end.
+// fn runs on its own so the caller that started it giving up does not fail the others waiting on it.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() ([]domain.Article, error)) ([]domain.Article, error) {
+	g.mutex.Lock()
+	f, ok := g.calls[key]
+	if !ok {
+		f = &flight{
+			done: make(chan struct{}),
+		}
+		g.calls[key] = f
+		g.running.Add(1)
+		go g.call(key, f, fn)
+	}
+	g.mutex.Unlock()
+
+	select {
+	case <-f.done:
+		return f.articles, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (g *flightGroup) call(key string, f *flight, fn func() ([]domain.Article, error)) {
+	defer g.running.Done()
+
+	f.articles, f.err = fn()
+
+	g.mutex.Lock()
+	delete(g.calls, key)
+	g.mutex.Unlock()
+	close(f.done)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"news-app/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waitingContext reports on waiting each time a caller starts waiting for it to end
+type waitingContext struct {
+	context.Context
+	waiting chan struct{}
+}
+
+func (c waitingContext) Done() <-chan struct{} {
+	c.waiting <- struct{}{}
+	return c.Context.Done()
+}
+
+// newWaitingContext returns a context that reports when up to n callers have started waiting on it
+func newWaitingContext(ctx context.Context, n int) waitingContext {
+	return waitingContext{Context: ctx, waiting: make(chan struct{}, n)}
+}
+
+func Test_flightGroup_do(t *testing.T) {
+	const (
+		someKey = "some-key"
+		waiters = 10
+	)
+	someArticles := []domain.Article{{Title: "some-title"}}
+
+	t.Run("should share one call between concurrent callers for the same key", func(t *testing.T) {
+		g := newFlightGroup(&sync.WaitGroup{})
+		release := make(chan struct{})
+
+		calls := 0
+		fn := func() ([]domain.Article, error) {
+			calls++
+			<-release
+			return someArticles, assert.AnError
+		}
+
+		ctx := newWaitingContext(context.Background(), waiters)
+
+		var wg sync.WaitGroup
+		results := make([][]domain.Article, waiters)
+		errs := make([]error, waiters)
+		for i := 0; i < waiters; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], errs[i] = g.do(ctx, someKey, fn)
+			}(i)
+		}
+		for i := 0; i < waiters; i++ {
+			<-ctx.waiting
+		}
+
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, 1, calls)
+		for i := 0; i < waiters; i++ {
+			assert.Equal(t, someArticles, results[i])
+			assert.ErrorIs(t, errs[i], assert.AnError)
+		}
+	})
+	t.Run("should finish the call for the others when the caller that started it gives up", func(t *testing.T) {
+		g := newFlightGroup(&sync.WaitGroup{})
+		release := make(chan struct{})
+
+		fn := func() ([]domain.Article, error) {
+			<-release
+			return someArticles, nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := g.do(ctx, someKey, fn)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		waiting := newWaitingContext(context.Background(), 1)
+		done := make(chan []domain.Article)
+		go func() {
+			articles, _ := g.do(waiting, someKey, func() ([]domain.Article, error) {
+				t.Error("the call in flight should be joined")
+				return nil, nil
+			})
+			done <- articles
+		}()
+		<-waiting.waiting
+
+		close(release)
+		assert.Equal(t, someArticles, <-done)
+	})
+	t.Run("should call fn again once the previous call has finished", func(t *testing.T) {
+		g := newFlightGroup(&sync.WaitGroup{})
+
+		calls := 0
+		fn := func() ([]domain.Article, error) {
+			calls++
+			return someArticles, nil
+		}
+
+		_, _ = g.do(context.Background(), someKey, fn)
+		_, _ = g.do(context.Background(), someKey, fn)
+
+		assert.Equal(t, 2, calls)
+		assert.Empty(t, g.calls)
+	})
+}
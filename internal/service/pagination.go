@@ -0,0 +1,85 @@
+package service
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"news-app/internal/domain"
+)
+
+// cursor is the position of the last article on a page
+type cursor struct {
+	published time.Time
+	id        string
+}
+
+// paginate returns the page of articles following page.Cursor, articles must already be sorted with sortArticles.
+// Cursors record the position of an article rather than an index so pages stay stable when new articles are published.
+func paginate(articles []domain.Article, page domain.PageRequest) (domain.ArticlePage, error) {
+	start := 0
+	if page.Cursor != "" {
+		c, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return domain.ArticlePage{}, err
+		}
+
+		start = len(articles)
+		for i, article := range articles {
+			if c.before(article) {
+				start = i
+				break
+			}
+		}
+	}
+
+	end := len(articles)
+	if page.Limit > 0 && start+page.Limit < end {
+		end = start + page.Limit
+	}
+
+	result := domain.ArticlePage{
+		Articles: articles[start:end],
+	}
+	if end < len(articles) {
+		result.NextCursor = encodeCursor(articles[end-1])
+	}
+	if result.Articles == nil {
+		result.Articles = []domain.Article{}
+	}
+
+	return result, nil
+}
+
+// before reports whether article comes after the cursor in sortArticles order
+func (c cursor) before(article domain.Article) bool {
+	if !article.Published.Equal(c.published) {
+		return article.Published.Before(c.published)
+	}
+
+	return article.ID > c.id
+}
+
+func encodeCursor(article domain.Article) string {
+	raw := article.Published.UTC().Format(time.RFC3339Nano) + "|" + article.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, domain.ErrInvalidCursor
+	}
+
+	published, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return cursor{}, domain.ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, published)
+	if err != nil {
+		return cursor{}, domain.ErrInvalidCursor
+	}
+
+	return cursor{published: t, id: id}, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_paginate(t *testing.T) {
+	var (
+		someTime     = time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)
+		someArticleA = domain.Article{ID: "a", Published: someTime}
+		someArticleB = domain.Article{ID: "b", Published: someTime}
+		someArticleC = domain.Article{ID: "c", Published: someTime.Add(-time.Hour)}
+		someArticleD = domain.Article{ID: "d", Published: someTime.Add(-2 * time.Hour)}
+		someArticles = []domain.Article{someArticleA, someArticleB, someArticleC, someArticleD}
+	)
+
+	t.Run("should return every article when there is no limit", func(t *testing.T) {
+		page, err := paginate(someArticles, domain.PageRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, someArticles, page.Articles)
+		assert.Empty(t, page.NextCursor)
+	})
+	t.Run("should walk through every page using the next cursor", func(t *testing.T) {
+		first, err := paginate(someArticles, domain.PageRequest{Limit: 3})
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Article{someArticleA, someArticleB, someArticleC}, first.Articles)
+		require.NotEmpty(t, first.NextCursor)
+
+		second, err := paginate(someArticles, domain.PageRequest{Limit: 3, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Article{someArticleD}, second.Articles)
+		assert.Empty(t, second.NextCursor)
+	})
+	t.Run("should split articles published at the same time across pages", func(t *testing.T) {
+		first, err := paginate(someArticles, domain.PageRequest{Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Article{someArticleA}, first.Articles)
+
+		second, err := paginate(someArticles, domain.PageRequest{Limit: 1, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Article{someArticleB}, second.Articles)
+	})
+	t.Run("should not repeat articles when newer articles are published between pages", func(t *testing.T) {
+		first, err := paginate(someArticles, domain.PageRequest{Limit: 2})
+		require.NoError(t, err)
+
+		someNewArticle := domain.Article{ID: "new", Published: someTime.Add(time.Hour)}
+		updated := append([]domain.Article{someNewArticle}, someArticles...)
+
+		second, err := paginate(updated, domain.PageRequest{Limit: 2, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Article{someArticleC, someArticleD}, second.Articles)
+	})
+	t.Run("should return an error for an invalid cursor", func(t *testing.T) {
+		_, err := paginate(someArticles, domain.PageRequest{Cursor: "not-a-cursor"})
+		assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+	})
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"news-app/internal/domain"
+)
+
+// Search returns articles matching a full text query ordered by relevance.
+// Feeds in the query may be either the ID of a registered feed or a feed URL.
+func (s service) Search(ctx context.Context, query domain.SearchQuery) (domain.SearchResults, error) {
+	feeds, err := s.feedStore.ListFeeds()
+	if err != nil {
+		return domain.SearchResults{}, fmt.Errorf("failed to list feeds: %w", err)
+	}
+
+	byID := make(map[string]domain.Subscription, len(feeds))
+	byURL := make(map[string]domain.Subscription, len(feeds))
+	for _, feed := range feeds {
+		byID[feed.ID] = feed
+		byURL[feed.URL] = feed
+	}
+
+	urls := make([]string, 0, len(query.Feeds))
+	for _, source := range query.Feeds {
+		if feed, ok := byID[source]; ok {
+			urls = append(urls, feed.URL)
+			continue
+		}
+		if !isURL(source) {
+			return domain.SearchResults{}, fmt.Errorf("failed to search feed %s: %w", source, domain.ErrFeedNotFound)
+		}
+		urls = append(urls, source)
+	}
+	query.Feeds = urls
+
+	results, err := s.index.Search(query)
+	if err != nil {
+		return domain.SearchResults{}, fmt.Errorf("failed to search articles: %w", err)
+	}
+
+	// the index only knows the feed URL so fill in the rest of the source for registered feeds
+	for i, result := range results.Results {
+		if feed, ok := byURL[result.Source.URL]; ok {
+			results.Results[i].Source = &domain.Source{FeedID: feed.ID, URL: feed.URL, Title: feed.Title}
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"news-app/internal/cache"
+	"news-app/internal/domain"
+	"news-app/internal/parser"
+	"news-app/internal/search"
+	"news-app/internal/store"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_service_Search(t *testing.T) {
+	const (
+		someID      = "some-id"
+		someFeedURL = "https://some-feed-url"
+		someURL     = "https://some-url"
+		someTitle   = "some-title"
+		someQuery   = "energy prices"
+	)
+	var someFeed = domain.Subscription{
+		ID:    someID,
+		URL:   someFeedURL,
+		Title: someTitle,
+	}
+
+	setup := func(t *testing.T) (Service, *store.MockFeedStore, *search.MockIndex) {
+		ctrl := gomock.NewController(t)
+		mockStore := store.NewMockFeedStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(parser.NewMockUniversalParser(ctrl), cache.NewMockCache(ctrl), mockStore, store.NewMockArticleStore(ctrl), mockIndex)
+
+		return service, mockStore, mockIndex
+	}
+
+	t.Run("should resolve feed ids and add registered feed details to the results", func(t *testing.T) {
+		service, mockStore, mockIndex := setup(t)
+
+		mockStore.EXPECT().ListFeeds().Return([]domain.Subscription{someFeed}, nil)
+		mockIndex.EXPECT().Search(domain.SearchQuery{
+			Query: someQuery,
+			Feeds: []string{someFeedURL, someURL},
+		}).Return(domain.SearchResults{
+			Results: []domain.SearchResult{
+				{Article: domain.Article{ID: "a", Source: &domain.Source{URL: someFeedURL}}, Score: 2},
+				{Article: domain.Article{ID: "b", Source: &domain.Source{URL: someURL}}, Score: 1},
+			},
+			Total: 2,
+		}, nil)
+
+		results, err := service.Search(context.Background(), domain.SearchQuery{
+			Query: someQuery,
+			Feeds: []string{someID, someURL},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, &domain.Source{FeedID: someID, URL: someFeedURL, Title: someTitle}, results.Results[0].Source)
+		assert.Equal(t, &domain.Source{URL: someURL}, results.Results[1].Source)
+	})
+	t.Run("should return not found for an unknown feed id", func(t *testing.T) {
+		service, mockStore, _ := setup(t)
+
+		mockStore.EXPECT().ListFeeds().Return(nil, nil)
+
+		_, err := service.Search(context.Background(), domain.SearchQuery{
+			Query: someQuery,
+			Feeds: []string{someID},
+		})
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+	})
+	t.Run("should return an error if the index rejects the query", func(t *testing.T) {
+		service, mockStore, mockIndex := setup(t)
+
+		mockStore.EXPECT().ListFeeds().Return(nil, nil)
+		mockIndex.EXPECT().Search(gomock.Any()).Return(domain.SearchResults{}, domain.ErrInvalidQuery)
+
+		_, err := service.Search(context.Background(), domain.SearchQuery{Query: "the"})
+		assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+	})
+}
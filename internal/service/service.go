@@ -4,51 +4,209 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"news-app/internal/cache"
 	"sort"
+	"sync"
+	"time"
 
 	"news-app/internal/domain"
+	"news-app/internal/logging"
 	"news-app/internal/parser"
+	"news-app/internal/search"
+	"news-app/internal/store"
 )
 
 // Service interface represents the service layer function available
 type Service interface {
-	GetArticles(context.Context, string) ([]domain.Article, error)
+	GetArticles(context.Context, string, domain.PageRequest) (domain.ArticlePage, error)
+	RefreshArticles(context.Context, string) ([]domain.Article, error)
+	GetTimeline(context.Context, []string, domain.TimelineOptions) (domain.Timeline, error)
+	Search(context.Context, domain.SearchQuery) (domain.SearchResults, error)
+
+	ListFeeds(context.Context) ([]domain.Subscription, error)
+	GetFeed(context.Context, string) (domain.Subscription, error)
+	AddFeed(context.Context, domain.Subscription) (domain.Subscription, error)
+	UpdateFeed(context.Context, string, domain.SubscriptionUpdate) (domain.Subscription, error)
+	RemoveFeed(context.Context, string) error
+
+	ListCacheEntries(context.Context) ([]domain.CacheEntry, error)
+	InvalidateFeed(context.Context, string) error
+	RefreshFeed(context.Context, string) error
+
+	Close()
 }
 
 // service is our internal representation of our service
 type service struct {
-	parser parser.UniversalParser
-	cache  cache.Cache
+	parser       parser.UniversalParser
+	cache        cache.Cache
+	feedStore    store.FeedStore
+	articleStore store.ArticleStore
+	index        search.Index
+	flights      *flightGroup
+
+	// background is cancelled on Close to stop refreshes that are not tied to a request
+	background context.Context
+	cancel     context.CancelFunc
+	// detached counts the refreshes that outlive the request that started them, revalidations and shared fetches, Close waits for them
+	detached *sync.WaitGroup
 }
 
 // NewService is a constructor for a Service
-func NewService(parser parser.UniversalParser, cache cache.Cache) Service {
+func NewService(parser parser.UniversalParser, cache cache.Cache, feedStore store.FeedStore, articleStore store.ArticleStore, index search.Index) Service {
+	background, cancel := context.WithCancel(context.Background())
+	detached := &sync.WaitGroup{}
+
 	return &service{
-		cache:  cache,
-		parser: parser,
+		cache:        cache,
+		parser:       parser,
+		feedStore:    feedStore,
+		articleStore: articleStore,
+		index:        index,
+		flights:      newFlightGroup(detached),
+		background:   background,
+		cancel:       cancel,
+		detached:     detached,
+	}
+}
+
+// GetArticles returns a page of articles given a feed URL
+func (s service) GetArticles(ctx context.Context, feedURL string, page domain.PageRequest) (domain.ArticlePage, error) {
+	articles, freshFor, stale, err := s.getArticles(ctx, feedURL)
+	if err != nil {
+		return domain.ArticlePage{}, err
+	}
+
+	result, err := paginate(articles, page)
+	if err != nil {
+		return domain.ArticlePage{}, err
 	}
+	result.Stale = stale
+	result.MaxAge = freshFor
+
+	return result, nil
 }
 
-// GetArticles returns a list of articles given a feed URL
-func (s service) GetArticles(ctx context.Context, feedURL string) ([]domain.Article, error) {
-	articles, ok := s.cache.GetArticlesFromCache(feedURL)
-	if !ok {
-		feed, err := s.parser.Parse(ctx, feedURL)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse feed: %w", err)
+// getArticles returns every article for a feed URL from the cache, parsing the feed on a miss, along with how much longer they stay fresh.
+// Stale articles are returned straight away and reported as stale while the feed is refreshed in the background,
+// if the refresh fails they keep being served until the cache's grace window passes.
+// Articles parsed on a miss are not fresh for any time as they may not have made it into the cache, such as when they are too large for it.
+func (s service) getArticles(ctx context.Context, feedURL string) ([]domain.Article, time.Duration, bool, error) {
+	articles, status, freshFor := s.cache.GetArticlesFromCache(feedURL)
+	switch status {
+	case cache.Fresh:
+		return articles, freshFor, false, nil
+	case cache.Stale:
+		if s.background.Err() == nil {
+			s.detached.Add(1)
+			go s.revalidate(logging.FromContext(ctx), feedURL)
 		}
+		return articles, 0, true, nil
+	default:
+		articles, err := s.RefreshArticles(ctx, feedURL)
+		return articles, 0, false, err
+	}
+}
+
+// revalidate refreshes a stale feed, it is detached from the request that found the stale entry so it is not cancelled when that request completes
+// but keeps its logger so the refresh is logged with the request's ID
+func (s service) revalidate(logger *logging.Logger, feedURL string) {
+	defer s.detached.Done()
+
+	if _, err := s.RefreshArticles(logging.NewContext(s.background, logger), feedURL); err != nil {
+		logger.Error("failed to revalidate stale feed", "feed_url", feedURL, "error", err)
+	}
+}
 
-		// sort articles in descending order by published date
-		sort.Slice(feed.Articles, func(i, j int) bool {
-			return feed.Articles[i].Published.After(feed.Articles[j].Published)
-		})
+// Close cancels background refreshes of stale feeds and waits for them and any shared fetches to exit,
+// so nothing is written to the cache or stores once they are closed
+func (s service) Close() {
+	s.cancel()
+	s.detached.Wait()
+}
 
-		articles = feed.Articles
+// RefreshArticles parses a feed URL regardless of what is cached and replaces the cache entry with the result.
+// Parsed articles are added to the article store and the cache holds the feed's full history, not just the articles currently in the feed.
+// Concurrent refreshes of the same feed share a single fetch, waiters get the same articles and error as the caller that made it.
+func (s service) RefreshArticles(ctx context.Context, feedURL string) ([]domain.Article, error) {
+	// the fetch is detached from the caller that started it so the others still get the articles if that caller goes away,
+	// it keeps the caller's logger so the fetch is logged with its request ID
+	shared := logging.NewContext(s.background, logging.FromContext(ctx))
+
+	return s.flights.do(ctx, feedURL, func() ([]domain.Article, error) {
+		return s.refreshArticles(shared, feedURL)
+	})
+}
 
-		s.cache.AddArticlesToCache(feedURL, articles)
+func (s service) refreshArticles(ctx context.Context, feedURL string) ([]domain.Article, error) {
+	feed, err := s.parser.Parse(ctx, feedURL)
+	if errors.Is(err, parser.ErrNotModified) {
+		articles, storeErr := s.articleStore.GetArticles(feedURL)
+		if storeErr != nil {
+			return nil, fmt.Errorf("failed to load stored articles: %w", storeErr)
+		}
+		if len(articles) > 0 {
+			sortArticles(articles)
+			s.cache.AddArticlesToCache(feedURL, articles)
+			return articles, nil
+		}
+
+		// nothing is stored for the articles the publisher says are unchanged, such as after they moved with the feed,
+		// so the feed is fetched in full rather than caching it as empty
+		logging.FromContext(ctx).Info("fetching unmodified feed in full as it has no stored articles", "feed_url", feedURL)
+		s.parser.Forget(feedURL)
+		feed, err = s.parser.Parse(ctx, feedURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	requestedURL := feedURL
+	if feed.MovedTo != "" && feed.MovedTo != feedURL {
+		s.moveFeed(ctx, feedURL, feed.MovedTo)
+		feedURL = feed.MovedTo
+	}
+
+	s.index.Add(feedURL, feed.Articles)
+	articles := s.storeArticles(ctx, feedURL, feed.Articles)
+
+	sortArticles(articles)
+
+	s.cache.AddArticlesToCache(feedURL, articles)
+	// clients still asking for the old URL of a moved feed are served from the cache too
+	if requestedURL != feedURL {
+		s.cache.AddArticlesToCache(requestedURL, articles)
 	}
 
 	return articles, nil
 }
+
+// storeArticles upserts freshly parsed articles and returns every article stored for the feed.
+// A failing store should not stop us serving the live feed so errors fall back to the parsed articles.
+func (s service) storeArticles(ctx context.Context, feedURL string, parsed []domain.Article) []domain.Article {
+	if err := s.articleStore.UpsertArticles(feedURL, parsed); err != nil {
+		logging.FromContext(ctx).Warn("failed to store articles", "feed_url", feedURL, "error", err)
+		return parsed
+	}
+
+	articles, err := s.articleStore.GetArticles(feedURL)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to load stored articles", "feed_url", feedURL, "error", err)
+		return parsed
+	}
+
+	return articles
+}
+
+// sortArticles sorts articles in descending order by published date, articles published at the same time are ordered by ID
+// so the order is stable for pagination
+func sortArticles(articles []domain.Article) {
+	sort.Slice(articles, func(i, j int) bool {
+		if !articles[i].Published.Equal(articles[j].Published) {
+			return articles[i].Published.After(articles[j].Published)
+		}
+		return articles[i].ID < articles[j].ID
+	})
+}
@@ -35,17 +35,191 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// AddFeed mocks base method.
+func (m *MockService) AddFeed(arg0 context.Context, arg1 domain.Subscription) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFeed", arg0, arg1)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddFeed indicates an expected call of AddFeed.
+func (mr *MockServiceMockRecorder) AddFeed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFeed", reflect.TypeOf((*MockService)(nil).AddFeed), arg0, arg1)
+}
+
+// Close mocks base method.
+func (m *MockService) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockServiceMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockService)(nil).Close))
+}
+
 // GetArticles mocks base method.
-func (m *MockService) GetArticles(arg0 context.Context, arg1 string) ([]domain.Article, error) {
+func (m *MockService) GetArticles(arg0 context.Context, arg1 string, arg2 domain.PageRequest) (domain.ArticlePage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetArticles", arg0, arg1)
-	ret0, _ := ret[0].([]domain.Article)
+	ret := m.ctrl.Call(m, "GetArticles", arg0, arg1, arg2)
+	ret0, _ := ret[0].(domain.ArticlePage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetArticles indicates an expected call of GetArticles.
-func (mr *MockServiceMockRecorder) GetArticles(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetArticles(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticles", reflect.TypeOf((*MockService)(nil).GetArticles), arg0, arg1, arg2)
+}
+
+// GetFeed mocks base method.
+func (m *MockService) GetFeed(arg0 context.Context, arg1 string) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeed", arg0, arg1)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeed indicates an expected call of GetFeed.
+func (mr *MockServiceMockRecorder) GetFeed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeed", reflect.TypeOf((*MockService)(nil).GetFeed), arg0, arg1)
+}
+
+// GetTimeline mocks base method.
+func (m *MockService) GetTimeline(arg0 context.Context, arg1 []string, arg2 domain.TimelineOptions) (domain.Timeline, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeline", arg0, arg1, arg2)
+	ret0, _ := ret[0].(domain.Timeline)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeline indicates an expected call of GetTimeline.
+func (mr *MockServiceMockRecorder) GetTimeline(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeline", reflect.TypeOf((*MockService)(nil).GetTimeline), arg0, arg1, arg2)
+}
+
+// InvalidateFeed mocks base method.
+func (m *MockService) InvalidateFeed(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateFeed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateFeed indicates an expected call of InvalidateFeed.
+func (mr *MockServiceMockRecorder) InvalidateFeed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateFeed", reflect.TypeOf((*MockService)(nil).InvalidateFeed), arg0, arg1)
+}
+
+// ListCacheEntries mocks base method.
+func (m *MockService) ListCacheEntries(arg0 context.Context) ([]domain.CacheEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCacheEntries", arg0)
+	ret0, _ := ret[0].([]domain.CacheEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCacheEntries indicates an expected call of ListCacheEntries.
+func (mr *MockServiceMockRecorder) ListCacheEntries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCacheEntries", reflect.TypeOf((*MockService)(nil).ListCacheEntries), arg0)
+}
+
+// ListFeeds mocks base method.
+func (m *MockService) ListFeeds(arg0 context.Context) ([]domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeeds", arg0)
+	ret0, _ := ret[0].([]domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeeds indicates an expected call of ListFeeds.
+func (mr *MockServiceMockRecorder) ListFeeds(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeeds", reflect.TypeOf((*MockService)(nil).ListFeeds), arg0)
+}
+
+// RefreshArticles mocks base method.
+func (m *MockService) RefreshArticles(arg0 context.Context, arg1 string) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshArticles", arg0, arg1)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshArticles indicates an expected call of RefreshArticles.
+func (mr *MockServiceMockRecorder) RefreshArticles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshArticles", reflect.TypeOf((*MockService)(nil).RefreshArticles), arg0, arg1)
+}
+
+// RefreshFeed mocks base method.
+func (m *MockService) RefreshFeed(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshFeed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshFeed indicates an expected call of RefreshFeed.
+func (mr *MockServiceMockRecorder) RefreshFeed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshFeed", reflect.TypeOf((*MockService)(nil).RefreshFeed), arg0, arg1)
+}
+
+// RemoveFeed mocks base method.
+func (m *MockService) RemoveFeed(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFeed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFeed indicates an expected call of RemoveFeed.
+func (mr *MockServiceMockRecorder) RemoveFeed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFeed", reflect.TypeOf((*MockService)(nil).RemoveFeed), arg0, arg1)
+}
+
+// Search mocks base method.
+func (m *MockService) Search(arg0 context.Context, arg1 domain.SearchQuery) (domain.SearchResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1)
+	ret0, _ := ret[0].(domain.SearchResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockServiceMockRecorder) Search(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockService)(nil).Search), arg0, arg1)
+}
+
+// UpdateFeed mocks base method.
+func (m *MockService) UpdateFeed(arg0 context.Context, arg1 string, arg2 domain.SubscriptionUpdate) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFeed", arg0, arg1, arg2)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFeed indicates an expected call of UpdateFeed.
+func (mr *MockServiceMockRecorder) UpdateFeed(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticles", reflect.TypeOf((*MockService)(nil).GetArticles), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFeed", reflect.TypeOf((*MockService)(nil).UpdateFeed), arg0, arg1, arg2)
 }
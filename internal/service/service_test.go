@@ -7,7 +7,11 @@ import (
 	"news-app/internal/cache"
 	"news-app/internal/domain"
 	"news-app/internal/parser"
+	"news-app/internal/search"
+	"news-app/internal/store"
+	"sync"
 	"testing"
+	"time"
 )
 
 func Test_service_GetArticles(t *testing.T) {
@@ -40,39 +44,483 @@ func Test_service_GetArticles(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockParser := parser.NewMockUniversalParser(ctrl)
 		mockCache := cache.NewMockCache(ctrl)
-		service := NewService(mockParser, mockCache)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), mockArticleStore, mockIndex)
 
-		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return(someArticles, true)
+		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return(someArticles, cache.Fresh, 30*time.Second)
 
-		articles, err := service.GetArticles(context.Background(), someFeedURL)
+		page, err := service.GetArticles(context.Background(), someFeedURL, domain.PageRequest{})
 		assert.NoError(t, err)
-		assert.Equal(t, someFeed.Articles, articles)
+		assert.Equal(t, someFeed.Articles, page.Articles)
+		assert.Equal(t, 30*time.Second, page.MaxAge)
+	})
+	t.Run("should return stale articles and refresh the feed in the background", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), mockArticleStore, mockIndex)
+
+		refreshed := make(chan struct{})
+
+		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return(someArticles, cache.Stale, time.Duration(0))
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(someFeed, nil)
+		mockIndex.EXPECT().Add(someFeedURL, gomock.Any())
+		mockArticleStore.EXPECT().UpsertArticles(someFeedURL, someArticles).Return(nil)
+		mockArticleStore.EXPECT().GetArticles(someFeedURL).Return(someArticles, nil)
+		mockCache.EXPECT().AddArticlesToCache(someFeedURL, someArticles).Do(func(string, []domain.Article) {
+			close(refreshed)
+		})
+
+		page, err := service.GetArticles(context.Background(), someFeedURL, domain.PageRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, someArticles, page.Articles)
+		assert.True(t, page.Stale)
+
+		<-refreshed
+	})
+	t.Run("should return stale articles without an error if the background refresh fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), store.NewMockArticleStore(ctrl), search.NewMockIndex(ctrl))
+
+		refreshed := make(chan struct{})
+
+		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return(someArticles, cache.Stale, time.Duration(0))
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).DoAndReturn(func(context.Context, string) (domain.Feed, error) {
+			close(refreshed)
+			return domain.Feed{}, assert.AnError
+		})
+
+		page, err := service.GetArticles(context.Background(), someFeedURL, domain.PageRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, someArticles, page.Articles)
+		assert.True(t, page.Stale)
+
+		<-refreshed
+	})
+	t.Run("should cancel background refreshes on close and not start more", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), store.NewMockArticleStore(ctrl), search.NewMockIndex(ctrl))
+
+		parsing := make(chan struct{})
+
+		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return(someArticles, cache.Stale, time.Duration(0)).Times(2)
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).DoAndReturn(func(ctx context.Context, _ string) (domain.Feed, error) {
+			close(parsing)
+			<-ctx.Done()
+			return domain.Feed{}, ctx.Err()
+		})
+
+		_, err := service.GetArticles(context.Background(), someFeedURL, domain.PageRequest{})
+		assert.NoError(t, err)
+
+		<-parsing
+		service.Close()
+
+		page, err := service.GetArticles(context.Background(), someFeedURL, domain.PageRequest{})
+		assert.NoError(t, err)
+		assert.True(t, page.Stale)
 	})
 	t.Run("should parse a list of articles and add to cache", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockParser := parser.NewMockUniversalParser(ctrl)
 		mockCache := cache.NewMockCache(ctrl)
-		service := NewService(mockParser, mockCache)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), mockArticleStore, mockIndex)
 
-		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return(nil, false)
+		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return(nil, cache.Missing, time.Duration(0))
 		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(someFeed, nil)
+		mockIndex.EXPECT().Add(someFeedURL, gomock.Any())
+		mockArticleStore.EXPECT().UpsertArticles(someFeedURL, someArticles).Return(nil)
+		mockArticleStore.EXPECT().GetArticles(someFeedURL).Return(someArticles, nil)
 		mockCache.EXPECT().AddArticlesToCache(someFeedURL, someArticles)
 
-		articles, err := service.GetArticles(context.Background(), someFeedURL)
+		page, err := service.GetArticles(context.Background(), someFeedURL, domain.PageRequest{})
 		assert.NoError(t, err)
-		assert.Equal(t, someFeed.Articles, articles)
+		assert.Equal(t, someFeed.Articles, page.Articles)
+		assert.Zero(t, page.MaxAge)
 	})
 	t.Run("should return an error if we fail to get a list of articles", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockParser := parser.NewMockUniversalParser(ctrl)
 		mockCache := cache.NewMockCache(ctrl)
-		service := NewService(mockParser, mockCache)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), mockArticleStore, mockIndex)
 
-		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return(nil, false)
+		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return(nil, cache.Missing, time.Duration(0))
 		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, assert.AnError)
 
-		articles, err := service.GetArticles(context.Background(), someFeedURL)
+		page, err := service.GetArticles(context.Background(), someFeedURL, domain.PageRequest{})
 		assert.Error(t, err)
+		assert.Empty(t, page.Articles)
+	})
+	t.Run("should parse a feed once for concurrent cache misses and share the result", func(t *testing.T) {
+		const requests = 10
+
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		svc := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), mockArticleStore, mockIndex)
+
+		parsing := make(chan struct{})
+		release := make(chan struct{})
+
+		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return(nil, cache.Missing, time.Duration(0)).Times(requests)
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).DoAndReturn(func(context.Context, string) (domain.Feed, error) {
+			close(parsing)
+			<-release
+			return someFeed, nil
+		})
+		mockIndex.EXPECT().Add(someFeedURL, gomock.Any())
+		mockArticleStore.EXPECT().UpsertArticles(someFeedURL, someArticles).Return(nil)
+		mockArticleStore.EXPECT().GetArticles(someFeedURL).Return(someArticles, nil)
+		mockCache.EXPECT().AddArticlesToCache(someFeedURL, someArticles)
+
+		ctx := newWaitingContext(context.Background(), requests)
+
+		var wg sync.WaitGroup
+		pages := make([]domain.ArticlePage, requests)
+		errs := make([]error, requests)
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				pages[i], errs[i] = svc.GetArticles(ctx, someFeedURL, domain.PageRequest{})
+			}(i)
+		}
+
+		<-parsing
+		for i := 0; i < requests; i++ {
+			<-ctx.waiting
+		}
+		close(release)
+		wg.Wait()
+
+		for i := 0; i < requests; i++ {
+			assert.NoError(t, errs[i])
+			assert.Equal(t, someArticles, pages[i].Articles)
+		}
+	})
+}
+
+func Test_service_RefreshArticles(t *testing.T) {
+	const someFeedURL = "some-feed-url"
+	var (
+		someOlderArticle = domain.Article{
+			Title:     "some-older-title",
+			Published: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		someNewerArticle = domain.Article{
+			Title:     "some-newer-title",
+			Published: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+		}
+	)
+	t.Run("should parse and cache articles without checking the cache", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), mockArticleStore, mockIndex)
+
+		parsed := []domain.Article{someOlderArticle, someNewerArticle}
+		expected := []domain.Article{someNewerArticle, someOlderArticle}
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{Articles: parsed}, nil)
+		mockIndex.EXPECT().Add(someFeedURL, gomock.Any())
+		mockArticleStore.EXPECT().UpsertArticles(someFeedURL, parsed).Return(nil)
+		mockArticleStore.EXPECT().GetArticles(someFeedURL).Return(parsed, nil)
+		mockCache.EXPECT().AddArticlesToCache(someFeedURL, expected)
+
+		articles, err := service.RefreshArticles(context.Background(), someFeedURL)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, articles)
+	})
+	t.Run("should include stored articles that are no longer in the feed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), mockArticleStore, mockIndex)
+
+		parsed := []domain.Article{someNewerArticle}
+		expected := []domain.Article{someNewerArticle, someOlderArticle}
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{Articles: parsed}, nil)
+		mockIndex.EXPECT().Add(someFeedURL, gomock.Any())
+		mockArticleStore.EXPECT().UpsertArticles(someFeedURL, parsed).Return(nil)
+		mockArticleStore.EXPECT().GetArticles(someFeedURL).Return([]domain.Article{someOlderArticle, someNewerArticle}, nil)
+		mockCache.EXPECT().AddArticlesToCache(someFeedURL, expected)
+
+		articles, err := service.RefreshArticles(context.Background(), someFeedURL)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, articles)
+	})
+	t.Run("should fall back to the parsed articles if the article store fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), mockArticleStore, mockIndex)
+
+		parsed := []domain.Article{someNewerArticle}
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{Articles: parsed}, nil)
+		mockIndex.EXPECT().Add(someFeedURL, gomock.Any())
+		mockArticleStore.EXPECT().UpsertArticles(someFeedURL, parsed).Return(assert.AnError)
+		mockCache.EXPECT().AddArticlesToCache(someFeedURL, parsed)
+
+		articles, err := service.RefreshArticles(context.Background(), someFeedURL)
+		assert.NoError(t, err)
+		assert.Equal(t, parsed, articles)
+	})
+	t.Run("should move subscriptions to a feed that has permanently moved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockFeedStore := store.NewMockFeedStore(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, mockFeedStore, mockArticleStore, mockIndex)
+
+		const someNewFeedURL = "some-new-feed-url"
+		parsed := []domain.Article{someNewerArticle}
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{Articles: parsed, MovedTo: someNewFeedURL}, nil)
+		mockFeedStore.EXPECT().ListFeeds().Return([]domain.Subscription{
+			{ID: "some-id", URL: someFeedURL},
+			{ID: "other-id", URL: "other-feed-url"},
+		}, nil)
+		mockFeedStore.EXPECT().UpdateFeed(domain.Subscription{ID: "some-id", URL: someNewFeedURL}).Return(domain.Subscription{}, nil)
+		// the history collected under the old URL moves with the feed
+		mockArticleStore.EXPECT().MoveArticles(someFeedURL, someNewFeedURL).Return(nil)
+		mockIndex.EXPECT().Move(someFeedURL, someNewFeedURL)
+		mockParser.EXPECT().Forget(someFeedURL)
+		mockIndex.EXPECT().Add(someNewFeedURL, gomock.Any())
+		mockArticleStore.EXPECT().UpsertArticles(someNewFeedURL, parsed).Return(nil)
+		mockArticleStore.EXPECT().GetArticles(someNewFeedURL).Return([]domain.Article{someNewerArticle, someOlderArticle}, nil)
+		// clients of the old URL are served the same articles from the cache
+		mockCache.EXPECT().AddArticlesToCache(someNewFeedURL, []domain.Article{someNewerArticle, someOlderArticle})
+		mockCache.EXPECT().AddArticlesToCache(someFeedURL, []domain.Article{someNewerArticle, someOlderArticle})
+
+		articles, err := service.RefreshArticles(context.Background(), someFeedURL)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Article{someNewerArticle, someOlderArticle}, articles)
+	})
+	t.Run("should still return the articles of a moved feed if its subscriptions and history cannot be moved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockFeedStore := store.NewMockFeedStore(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, mockFeedStore, mockArticleStore, mockIndex)
+
+		parsed := []domain.Article{someNewerArticle}
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{Articles: parsed, MovedTo: "some-new-feed-url"}, nil)
+		mockFeedStore.EXPECT().ListFeeds().Return([]domain.Subscription{{ID: "some-id", URL: someFeedURL}}, nil)
+		mockFeedStore.EXPECT().UpdateFeed(gomock.Any()).Return(domain.Subscription{}, domain.ErrFeedAlreadyExists)
+		mockArticleStore.EXPECT().MoveArticles(someFeedURL, "some-new-feed-url").Return(assert.AnError)
+		mockIndex.EXPECT().Move(someFeedURL, "some-new-feed-url")
+		mockParser.EXPECT().Forget(someFeedURL)
+		mockIndex.EXPECT().Add("some-new-feed-url", gomock.Any())
+		mockArticleStore.EXPECT().UpsertArticles("some-new-feed-url", parsed).Return(nil)
+		mockArticleStore.EXPECT().GetArticles("some-new-feed-url").Return(parsed, nil)
+		mockCache.EXPECT().AddArticlesToCache("some-new-feed-url", parsed)
+		mockCache.EXPECT().AddArticlesToCache(someFeedURL, parsed)
+
+		articles, err := service.RefreshArticles(context.Background(), someFeedURL)
+		assert.NoError(t, err)
+		assert.Equal(t, parsed, articles)
+	})
+	t.Run("should finish a shared fetch for the other callers when the caller that started it goes away", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), mockArticleStore, mockIndex)
+
+		parsed := []domain.Article{someNewerArticle}
+		parsing := make(chan struct{})
+		release := make(chan struct{})
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).DoAndReturn(func(ctx context.Context, _ string) (domain.Feed, error) {
+			close(parsing)
+			<-release
+			return domain.Feed{Articles: parsed}, ctx.Err()
+		})
+		mockIndex.EXPECT().Add(someFeedURL, gomock.Any())
+		mockArticleStore.EXPECT().UpsertArticles(someFeedURL, parsed).Return(nil)
+		mockArticleStore.EXPECT().GetArticles(someFeedURL).Return(parsed, nil)
+		mockCache.EXPECT().AddArticlesToCache(someFeedURL, parsed)
+
+		leader, cancel := context.WithCancel(context.Background())
+		leaderErr := make(chan error)
+		go func() {
+			_, err := service.RefreshArticles(leader, someFeedURL)
+			leaderErr <- err
+		}()
+		<-parsing
+
+		waiter := newWaitingContext(context.Background(), 1)
+		done := make(chan []domain.Article)
+		go func() {
+			articles, err := service.RefreshArticles(waiter, someFeedURL)
+			assert.NoError(t, err)
+			done <- articles
+		}()
+		<-waiter.waiting
+
+		cancel()
+		assert.ErrorIs(t, <-leaderErr, context.Canceled)
+
+		close(release)
+		assert.Equal(t, parsed, <-done)
+	})
+	t.Run("should wait on close for a shared fetch to finish once the caller that started it has gone away", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), mockArticleStore, mockIndex)
+
+		parsed := []domain.Article{someNewerArticle}
+		parsing := make(chan struct{})
+		release := make(chan struct{})
+		cached := false
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).DoAndReturn(func(context.Context, string) (domain.Feed, error) {
+			close(parsing)
+			<-release
+			return domain.Feed{Articles: parsed}, nil
+		})
+		mockIndex.EXPECT().Add(someFeedURL, gomock.Any())
+		mockArticleStore.EXPECT().UpsertArticles(someFeedURL, parsed).Return(nil)
+		mockArticleStore.EXPECT().GetArticles(someFeedURL).Return(parsed, nil)
+		mockCache.EXPECT().AddArticlesToCache(someFeedURL, parsed).Do(func(string, []domain.Article) {
+			cached = true
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		callerErr := make(chan error)
+		go func() {
+			_, err := service.RefreshArticles(ctx, someFeedURL)
+			callerErr <- err
+		}()
+		<-parsing
+		cancel()
+		assert.ErrorIs(t, <-callerErr, context.Canceled)
+
+		closed := make(chan struct{})
+		go func() {
+			service.Close()
+			close(closed)
+		}()
+
+		select {
+		case <-closed:
+			t.Fatal("close should wait for the shared fetch")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		close(release)
+		<-closed
+		assert.True(t, cached)
+	})
+	t.Run("should re-cache stored articles without indexing if the feed has not been modified", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), mockArticleStore, search.NewMockIndex(ctrl))
+
+		expected := []domain.Article{someNewerArticle, someOlderArticle}
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, parser.ErrNotModified)
+		mockArticleStore.EXPECT().GetArticles(someFeedURL).Return([]domain.Article{someOlderArticle, someNewerArticle}, nil)
+		mockCache.EXPECT().AddArticlesToCache(someFeedURL, expected)
+
+		articles, err := service.RefreshArticles(context.Background(), someFeedURL)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, articles)
+	})
+	t.Run("should fetch a moved feed in full when its old URL has not been modified and nothing is stored under it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockFeedStore := store.NewMockFeedStore(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, mockFeedStore, mockArticleStore, mockIndex)
+
+		const someNewFeedURL = "some-new-feed-url"
+		parsed := []domain.Article{someNewerArticle}
+		moved := domain.Feed{Articles: parsed, MovedTo: someNewFeedURL}
+
+		// the first refresh follows the redirect, then the publisher reports the old URL unchanged
+		// but its articles were moved to the new URL so the feed is fetched in full again
+		gomock.InOrder(
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(moved, nil),
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, parser.ErrNotModified),
+			mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(moved, nil),
+		)
+		mockArticleStore.EXPECT().GetArticles(someFeedURL).Return(nil, nil)
+		mockParser.EXPECT().Forget(someFeedURL).Times(3)
+		mockFeedStore.EXPECT().ListFeeds().Return(nil, nil).Times(2)
+		mockArticleStore.EXPECT().MoveArticles(someFeedURL, someNewFeedURL).Return(nil).Times(2)
+		mockIndex.EXPECT().Move(someFeedURL, someNewFeedURL).Times(2)
+		mockIndex.EXPECT().Add(someNewFeedURL, gomock.Any()).Times(2)
+		mockArticleStore.EXPECT().UpsertArticles(someNewFeedURL, parsed).Return(nil).Times(2)
+		mockArticleStore.EXPECT().GetArticles(someNewFeedURL).Return(parsed, nil).Times(2)
+		mockCache.EXPECT().AddArticlesToCache(someNewFeedURL, parsed).Times(2)
+		mockCache.EXPECT().AddArticlesToCache(someFeedURL, parsed).Times(2)
+
+		for i := 0; i < 2; i++ {
+			articles, err := service.RefreshArticles(context.Background(), someFeedURL)
+			assert.NoError(t, err)
+			assert.Equal(t, parsed, articles)
+		}
+	})
+	t.Run("should return an error if the feed has not been modified and the article store fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		service := NewService(mockParser, cache.NewMockCache(ctrl), store.NewMockFeedStore(ctrl), mockArticleStore, search.NewMockIndex(ctrl))
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, parser.ErrNotModified)
+		mockArticleStore.EXPECT().GetArticles(someFeedURL).Return(nil, assert.AnError)
+
+		articles, err := service.RefreshArticles(context.Background(), someFeedURL)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Empty(t, articles)
+	})
+	t.Run("should return an error and leave the cache alone if parsing fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockArticleStore := store.NewMockArticleStore(ctrl)
+		mockIndex := search.NewMockIndex(ctrl)
+		service := NewService(mockParser, mockCache, store.NewMockFeedStore(ctrl), mockArticleStore, mockIndex)
+
+		mockParser.EXPECT().Parse(gomock.Any(), someFeedURL).Return(domain.Feed{}, assert.AnError)
+
+		articles, err := service.RefreshArticles(context.Background(), someFeedURL)
+		assert.ErrorIs(t, err, assert.AnError)
 		assert.Empty(t, articles)
 	})
 }
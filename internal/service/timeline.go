@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"news-app/internal/domain"
+)
+
+// timelineConcurrency is the most feeds fetched at once for a timeline
+const timelineConcurrency = 8
+
+// GetTimeline fetches several feeds concurrently and merges their articles into a single list ordered by published date.
+// Each source may be either the ID of a registered feed or a feed URL, when no sources are given every registered feed is used.
+// Feeds that fail are reported in the Timeline errors, an error is only returned if every feed fails.
+func (s service) GetTimeline(ctx context.Context, sources []string, opts domain.TimelineOptions) (domain.Timeline, error) {
+	resolved, timeline, err := s.resolveSources(sources)
+	if err != nil {
+		return domain.Timeline{}, err
+	}
+
+	type result struct {
+		source   domain.Source
+		articles []domain.Article
+		stale    bool
+		err      error
+	}
+
+	results := make([]result, len(resolved))
+	slots := make(chan struct{}, timelineConcurrency)
+	var wg sync.WaitGroup
+	for i, source := range resolved {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int, source domain.Source) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			articles, _, stale, err := s.getArticles(ctx, source.URL)
+			results[i] = result{source: source, articles: articles, stale: stale, err: err}
+		}(i, source)
+	}
+	wg.Wait()
+
+	var articles []domain.Article
+	var failed int
+	var lastErr error
+	var stale bool
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			lastErr = r.err
+			timeline.Errors = append(timeline.Errors, domain.FeedError{
+				Source: r.source.URL,
+				Error:  r.err.Error(),
+			})
+			continue
+		}
+
+		stale = stale || r.stale
+		source := r.source
+		for _, article := range r.articles {
+			// articles are shared with the cache so tag a copy
+			article.Source = &source
+			articles = append(articles, article)
+		}
+	}
+
+	if len(resolved) > 0 && failed == len(resolved) {
+		return domain.Timeline{}, fmt.Errorf("failed to fetch any feed: %w", lastErr)
+	}
+
+	sortArticles(articles)
+
+	timeline.ArticlePage, err = paginate(articles, opts.Page)
+	if err != nil {
+		return domain.Timeline{}, err
+	}
+	timeline.Stale = stale
+
+	return timeline, nil
+}
+
+// resolveSources turns feed IDs and URLs into a de-duplicated list of sources.
+// Sources that are neither a registered feed nor a URL are returned as errors on the Timeline.
+func (s service) resolveSources(sources []string) ([]domain.Source, domain.Timeline, error) {
+	var timeline domain.Timeline
+
+	if len(sources) == 0 {
+		feeds, err := s.feedStore.ListFeeds()
+		if err != nil {
+			return nil, timeline, fmt.Errorf("failed to list feeds: %w", err)
+		}
+
+		for _, feed := range feeds {
+			sources = append(sources, feed.ID)
+		}
+	}
+
+	var resolved []domain.Source
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		var resolvedSource domain.Source
+
+		feed, err := s.feedStore.GetFeed(source)
+		switch {
+		case err == nil:
+			resolvedSource = domain.Source{FeedID: feed.ID, URL: feed.URL, Title: feed.Title}
+		case errors.Is(err, domain.ErrFeedNotFound) && isURL(source):
+			resolvedSource = domain.Source{URL: source}
+		default:
+			timeline.Errors = append(timeline.Errors, domain.FeedError{Source: source, Error: err.Error()})
+			continue
+		}
+
+		if seen[resolvedSource.URL] {
+			continue
+		}
+		seen[resolvedSource.URL] = true
+		resolved = append(resolved, resolvedSource)
+	}
+
+	return resolved, timeline, nil
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
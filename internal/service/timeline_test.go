@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"news-app/internal/cache"
+	"news-app/internal/domain"
+	"news-app/internal/parser"
+	"news-app/internal/search"
+	"news-app/internal/store"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_service_GetTimeline(t *testing.T) {
+	const (
+		someID      = "some-id"
+		someFeedURL = "https://some-feed-url"
+		someOtherID = "some-other-id"
+		someURL     = "https://some-url"
+		someTitle   = "some-title"
+	)
+	var (
+		someFeed = domain.Subscription{
+			ID:    someID,
+			URL:   someFeedURL,
+			Title: someTitle,
+		}
+		someOldestArticle = domain.Article{
+			Title:     "some-oldest-title",
+			Published: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		someMiddleArticle = domain.Article{
+			Title:     "some-middle-title",
+			Published: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+		}
+		someNewestArticle = domain.Article{
+			Title:     "some-newest-title",
+			Published: time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC),
+		}
+		someFeedSource = domain.Source{FeedID: someID, URL: someFeedURL, Title: someTitle}
+		someURLSource  = domain.Source{URL: someURL}
+	)
+
+	tagged := func(article domain.Article, source domain.Source) domain.Article {
+		article.Source = &source
+		return article
+	}
+
+	setup := func(t *testing.T) (Service, *parser.MockUniversalParser, *cache.MockCache, *store.MockFeedStore) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		mockCache := cache.NewMockCache(ctrl)
+		mockStore := store.NewMockFeedStore(ctrl)
+		service := NewService(mockParser, mockCache, mockStore, store.NewMockArticleStore(ctrl), search.NewMockIndex(ctrl))
+
+		return service, mockParser, mockCache, mockStore
+	}
+
+	t.Run("should merge articles from feed ids and urls in published order", func(t *testing.T) {
+		service, _, mockCache, mockStore := setup(t)
+
+		mockStore.EXPECT().GetFeed(someID).Return(someFeed, nil)
+		mockStore.EXPECT().GetFeed(someURL).Return(domain.Subscription{}, domain.ErrFeedNotFound)
+		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return([]domain.Article{someNewestArticle, someOldestArticle}, cache.Fresh, time.Minute)
+		mockCache.EXPECT().GetArticlesFromCache(someURL).Return([]domain.Article{someMiddleArticle}, cache.Fresh, time.Minute)
+
+		timeline, err := service.GetTimeline(context.Background(), []string{someID, someURL}, domain.TimelineOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []domain.Article{
+			tagged(someNewestArticle, someFeedSource),
+			tagged(someMiddleArticle, someURLSource),
+			tagged(someOldestArticle, someFeedSource),
+		}, timeline.Articles)
+		assert.Empty(t, timeline.Errors)
+		assert.False(t, timeline.Stale)
+	})
+	t.Run("should mark the timeline as stale if any feed was served stale", func(t *testing.T) {
+		service, mockParser, mockCache, mockStore := setup(t)
+
+		refreshed := make(chan struct{})
+
+		mockStore.EXPECT().GetFeed(someID).Return(someFeed, nil)
+		mockStore.EXPECT().GetFeed(someURL).Return(domain.Subscription{}, domain.ErrFeedNotFound)
+		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return([]domain.Article{someOldestArticle}, cache.Fresh, time.Minute)
+		mockCache.EXPECT().GetArticlesFromCache(someURL).Return([]domain.Article{someMiddleArticle}, cache.Stale, time.Duration(0))
+		mockParser.EXPECT().Parse(gomock.Any(), someURL).DoAndReturn(func(context.Context, string) (domain.Feed, error) {
+			close(refreshed)
+			return domain.Feed{}, assert.AnError
+		})
+
+		timeline, err := service.GetTimeline(context.Background(), []string{someID, someURL}, domain.TimelineOptions{})
+		require.NoError(t, err)
+
+		assert.Len(t, timeline.Articles, 2)
+		assert.True(t, timeline.Stale)
+
+		<-refreshed
+	})
+	t.Run("should return partial results and errors when some feeds fail", func(t *testing.T) {
+		service, mockParser, mockCache, mockStore := setup(t)
+
+		mockStore.EXPECT().GetFeed(someID).Return(someFeed, nil)
+		mockStore.EXPECT().GetFeed(someOtherID).Return(domain.Subscription{}, domain.ErrFeedNotFound)
+		mockStore.EXPECT().GetFeed(someURL).Return(domain.Subscription{}, domain.ErrFeedNotFound)
+		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return([]domain.Article{someOldestArticle}, cache.Fresh, time.Minute)
+		mockCache.EXPECT().GetArticlesFromCache(someURL).Return(nil, cache.Missing, time.Duration(0))
+		mockParser.EXPECT().Parse(gomock.Any(), someURL).Return(domain.Feed{}, assert.AnError)
+
+		timeline, err := service.GetTimeline(context.Background(), []string{someID, someOtherID, someURL}, domain.TimelineOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []domain.Article{tagged(someOldestArticle, someFeedSource)}, timeline.Articles)
+		require.Len(t, timeline.Errors, 2)
+		assert.Equal(t, someOtherID, timeline.Errors[0].Source)
+		assert.Equal(t, someURL, timeline.Errors[1].Source)
+	})
+	t.Run("should return an error when every feed fails", func(t *testing.T) {
+		service, mockParser, mockCache, mockStore := setup(t)
+
+		mockStore.EXPECT().GetFeed(someURL).Return(domain.Subscription{}, domain.ErrFeedNotFound)
+		mockCache.EXPECT().GetArticlesFromCache(someURL).Return(nil, cache.Missing, time.Duration(0))
+		mockParser.EXPECT().Parse(gomock.Any(), someURL).Return(domain.Feed{}, assert.AnError)
+
+		_, err := service.GetTimeline(context.Background(), []string{someURL}, domain.TimelineOptions{})
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+	t.Run("should use every registered feed when no sources are given and apply the limit", func(t *testing.T) {
+		service, _, mockCache, mockStore := setup(t)
+
+		mockStore.EXPECT().ListFeeds().Return([]domain.Subscription{someFeed}, nil)
+		mockStore.EXPECT().GetFeed(someID).Return(someFeed, nil)
+		mockCache.EXPECT().GetArticlesFromCache(someFeedURL).Return([]domain.Article{someNewestArticle, someOldestArticle}, cache.Fresh, time.Minute)
+
+		timeline, err := service.GetTimeline(context.Background(), nil, domain.TimelineOptions{Page: domain.PageRequest{Limit: 1}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []domain.Article{tagged(someNewestArticle, someFeedSource)}, timeline.Articles)
+		assert.NotEmpty(t, timeline.NextCursor)
+	})
+	t.Run("should limit how many feeds are fetched at once", func(t *testing.T) {
+		service, _, mockCache, mockStore := setup(t)
+
+		const sources = timelineConcurrency + 1
+		started := make(chan struct{}, sources)
+		release := make(chan struct{})
+
+		var urls []string
+		for i := 0; i < sources; i++ {
+			urls = append(urls, fmt.Sprintf("%s/%d", someURL, i))
+		}
+		mockStore.EXPECT().GetFeed(gomock.Any()).Return(domain.Subscription{}, domain.ErrFeedNotFound).Times(sources)
+		mockCache.EXPECT().GetArticlesFromCache(gomock.Any()).DoAndReturn(func(string) ([]domain.Article, cache.Status, time.Duration) {
+			started <- struct{}{}
+			<-release
+			return nil, cache.Fresh, time.Minute
+		}).Times(sources)
+
+		done := make(chan error)
+		go func() {
+			_, err := service.GetTimeline(context.Background(), urls, domain.TimelineOptions{})
+			done <- err
+		}()
+
+		for i := 0; i < timelineConcurrency; i++ {
+			<-started
+		}
+		select {
+		case <-started:
+			t.Fatal("no more feeds should be fetched until one finishes")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		close(release)
+		assert.NoError(t, <-done)
+	})
+}
@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"news-app/internal/domain"
+)
+
+// articlesBucket is the top level bucket, it holds a nested bucket per feed URL keyed by article ID
+var articlesBucket = []byte("articles")
+
+// ArticleStore is an interface for persisting every article we have parsed so history outlives the feed
+type ArticleStore interface {
+	UpsertArticles(feedURL string, articles []domain.Article) error
+	GetArticles(feedURL string) ([]domain.Article, error)
+	MoveArticles(from, to string) error
+	ForEachFeed(fn func(feedURL string, articles []domain.Article) error) error
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// boltArticleStore is the internal representation of an ArticleStore backed by an embedded bolt database
+type boltArticleStore struct {
+	db *bolt.DB
+}
+
+// NewBoltArticleStore is a constructor for an ArticleStore which persists articles to a bolt database at path
+func NewBoltArticleStore(path string) (ArticleStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open article store: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(articlesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create article store: %w", err)
+	}
+
+	return &boltArticleStore{
+		db: db,
+	}, nil
+}
+
+// UpsertArticles adds articles to a feed's history, articles that are already stored are overwritten with the latest version.
+// Articles without an ID cannot be recognised on later fetches so are skipped.
+func (s *boltArticleStore) UpsertArticles(feedURL string, articles []domain.Article) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		feed, err := tx.Bucket(articlesBucket).CreateBucketIfNotExists([]byte(feedURL))
+		if err != nil {
+			return err
+		}
+
+		for _, article := range articles {
+			if article.ID == "" {
+				continue
+			}
+
+			// the source is added per request so is not part of the stored article
+			article.Source = nil
+
+			value, err := json.Marshal(article)
+			if err != nil {
+				return err
+			}
+
+			if err := feed.Put([]byte(article.ID), value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upsert articles: %w", err)
+	}
+
+	return nil
+}
+
+// GetArticles returns every article ever stored for a feed in no particular order
+func (s *boltArticleStore) GetArticles(feedURL string) ([]domain.Article, error) {
+	var articles []domain.Article
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		feed := tx.Bucket(articlesBucket).Bucket([]byte(feedURL))
+		if feed == nil {
+			return nil
+		}
+
+		var err error
+		articles, err = decodeArticles(feed)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get articles: %w", err)
+	}
+
+	return articles, nil
+}
+
+// MoveArticles moves a feed's history to the URL it moved to, articles already stored under that URL are kept instead
+func (s *boltArticleStore) MoveArticles(from, to string) error {
+	if from == to {
+		return nil
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		articles := tx.Bucket(articlesBucket)
+		source := articles.Bucket([]byte(from))
+		if source == nil {
+			return nil
+		}
+
+		target, err := articles.CreateBucketIfNotExists([]byte(to))
+		if err != nil {
+			return err
+		}
+
+		err = source.ForEach(func(id, value []byte) error {
+			if target.Get(id) != nil {
+				return nil
+			}
+			return target.Put(id, value)
+		})
+		if err != nil {
+			return err
+		}
+
+		return articles.DeleteBucket([]byte(from))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move articles: %w", err)
+	}
+
+	return nil
+}
+
+// ForEachFeed calls fn with every stored article for each feed, iteration stops at the first error
+func (s *boltArticleStore) ForEachFeed(fn func(feedURL string, articles []domain.Article) error) error {
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(articlesBucket).ForEach(func(feedURL, _ []byte) error {
+			articles, err := decodeArticles(tx.Bucket(articlesBucket).Bucket(feedURL))
+			if err != nil {
+				return err
+			}
+
+			return fn(string(feedURL), articles)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to iterate articles: %w", err)
+	}
+
+	return nil
+}
+
+func decodeArticles(feed *bolt.Bucket) ([]domain.Article, error) {
+	var articles []domain.Article
+
+	err := feed.ForEach(func(_, value []byte) error {
+		var article domain.Article
+		if err := json.Unmarshal(value, &article); err != nil {
+			return err
+		}
+
+		articles = append(articles, article)
+		return nil
+	})
+
+	return articles, err
+}
+
+// Ping reports whether the database is open and can be read
+func (s *boltArticleStore) Ping(context.Context) error {
+	if err := s.db.View(func(tx *bolt.Tx) error { return nil }); err != nil {
+		return fmt.Errorf("failed to read article store: %w", err)
+	}
+
+	return nil
+}
+
+// Close releases the lock on the database file
+func (s *boltArticleStore) Close() error {
+	return s.db.Close()
+}
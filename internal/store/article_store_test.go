@@ -0,0 +1,159 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_boltArticleStore(t *testing.T) {
+	const (
+		someURL      = "https://some-url.com"
+		someOtherURL = "https://some-other-url.com"
+	)
+	var (
+		someArticle = domain.Article{
+			ID:        "some-id",
+			Title:     "some-title",
+			Published: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		someOtherArticle = domain.Article{
+			ID:        "some-other-id",
+			Title:     "some-other-title",
+			Published: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+		}
+	)
+
+	t.Run("should return articles that have been upserted", func(t *testing.T) {
+		store, err := NewBoltArticleStore(filepath.Join(t.TempDir(), "articles.db"))
+		require.NoError(t, err)
+		defer store.Close()
+
+		err = store.UpsertArticles(someURL, []domain.Article{someArticle})
+		require.NoError(t, err)
+
+		articles, err := store.GetArticles(someURL)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Article{someArticle}, articles)
+	})
+	t.Run("should keep articles that are no longer in the feed and update ones that are", func(t *testing.T) {
+		store, err := NewBoltArticleStore(filepath.Join(t.TempDir(), "articles.db"))
+		require.NoError(t, err)
+		defer store.Close()
+
+		err = store.UpsertArticles(someURL, []domain.Article{someArticle})
+		require.NoError(t, err)
+
+		updated := someOtherArticle
+		err = store.UpsertArticles(someURL, []domain.Article{updated})
+		require.NoError(t, err)
+
+		updated.Title = "some-updated-title"
+		err = store.UpsertArticles(someURL, []domain.Article{updated})
+		require.NoError(t, err)
+
+		articles, err := store.GetArticles(someURL)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []domain.Article{someArticle, updated}, articles)
+	})
+	t.Run("should keep feeds separate", func(t *testing.T) {
+		store, err := NewBoltArticleStore(filepath.Join(t.TempDir(), "articles.db"))
+		require.NoError(t, err)
+		defer store.Close()
+
+		err = store.UpsertArticles(someURL, []domain.Article{someArticle})
+		require.NoError(t, err)
+
+		articles, err := store.GetArticles(someOtherURL)
+		require.NoError(t, err)
+		assert.Empty(t, articles)
+	})
+	t.Run("should move the history of a feed to another url keeping articles already stored there", func(t *testing.T) {
+		store, err := NewBoltArticleStore(filepath.Join(t.TempDir(), "articles.db"))
+		require.NoError(t, err)
+		defer store.Close()
+
+		stale := someOtherArticle
+		stale.Title = "some-stale-title"
+		require.NoError(t, store.UpsertArticles(someURL, []domain.Article{someArticle, stale}))
+		require.NoError(t, store.UpsertArticles(someOtherURL, []domain.Article{someOtherArticle}))
+
+		require.NoError(t, store.MoveArticles(someURL, someOtherURL))
+
+		articles, err := store.GetArticles(someOtherURL)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []domain.Article{someArticle, someOtherArticle}, articles)
+
+		articles, err = store.GetArticles(someURL)
+		require.NoError(t, err)
+		assert.Empty(t, articles)
+
+		// nothing is stored under the old url any more
+		assert.NoError(t, store.MoveArticles(someURL, someOtherURL))
+	})
+	t.Run("should skip articles without an id and drop the source", func(t *testing.T) {
+		store, err := NewBoltArticleStore(filepath.Join(t.TempDir(), "articles.db"))
+		require.NoError(t, err)
+		defer store.Close()
+
+		tagged := someArticle
+		tagged.Source = &domain.Source{URL: someURL}
+		err = store.UpsertArticles(someURL, []domain.Article{tagged, {Title: "no-id"}})
+		require.NoError(t, err)
+
+		articles, err := store.GetArticles(someURL)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Article{someArticle}, articles)
+	})
+	t.Run("should load articles persisted by a previous store", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "articles.db")
+		store, err := NewBoltArticleStore(path)
+		require.NoError(t, err)
+
+		err = store.UpsertArticles(someURL, []domain.Article{someArticle})
+		require.NoError(t, err)
+		require.NoError(t, store.Close())
+
+		reopened, err := NewBoltArticleStore(path)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		articles, err := reopened.GetArticles(someURL)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Article{someArticle}, articles)
+	})
+	t.Run("should call fn with the articles for every feed", func(t *testing.T) {
+		store, err := NewBoltArticleStore(filepath.Join(t.TempDir(), "articles.db"))
+		require.NoError(t, err)
+		defer store.Close()
+
+		require.NoError(t, store.UpsertArticles(someURL, []domain.Article{someArticle}))
+		require.NoError(t, store.UpsertArticles(someOtherURL, []domain.Article{someOtherArticle}))
+
+		feeds := make(map[string][]domain.Article)
+		err = store.ForEachFeed(func(feedURL string, articles []domain.Article) error {
+			feeds[feedURL] = articles
+			return nil
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string][]domain.Article{
+			someURL:      {someArticle},
+			someOtherURL: {someOtherArticle},
+		}, feeds)
+	})
+	t.Run("should report whether the database can be read", func(t *testing.T) {
+		store, err := NewBoltArticleStore(filepath.Join(t.TempDir(), "articles.db"))
+		require.NoError(t, err)
+		assert.NoError(t, store.Ping(context.Background()))
+
+		require.NoError(t, store.Close())
+		assert.Error(t, store.Ping(context.Background()))
+	})
+}
@@ -0,0 +1,219 @@
+//go:generate mockgen -package=store -destination=./store_mock.go . FeedStore,ArticleStore
+
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"news-app/internal/domain"
+)
+
+// FeedStore is an interface for persisting feed subscriptions
+type FeedStore interface {
+	ListFeeds() ([]domain.Subscription, error)
+	GetFeed(id string) (domain.Subscription, error)
+	AddFeed(feed domain.Subscription) (domain.Subscription, error)
+	UpdateFeed(feed domain.Subscription) (domain.Subscription, error)
+	RemoveFeed(id string) error
+	Ping(ctx context.Context) error
+}
+
+// fileFeedStore is the internal representation of a FeedStore backed by a JSON file
+type fileFeedStore struct {
+	path string
+
+	mutex sync.RWMutex
+	feeds []domain.Subscription
+}
+
+// NewFileFeedStore is a constructor for a FeedStore which persists subscriptions to the file at path.
+// Any subscriptions already in the file are loaded.
+func NewFileFeedStore(path string) (FeedStore, error) {
+	store := &fileFeedStore{
+		path: path,
+	}
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read feed store: %w", err)
+	}
+
+	if err := json.Unmarshal(body, &store.feeds); err != nil {
+		return nil, fmt.Errorf("failed to decode feed store: %w", err)
+	}
+
+	return store, nil
+}
+
+// ListFeeds returns every subscription in the order they were added
+func (s *fileFeedStore) ListFeeds() ([]domain.Subscription, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	feeds := make([]domain.Subscription, len(s.feeds))
+	copy(feeds, s.feeds)
+
+	return feeds, nil
+}
+
+// GetFeed returns the subscription with the given id or domain.ErrFeedNotFound
+func (s *fileFeedStore) GetFeed(id string) (domain.Subscription, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return domain.Subscription{}, domain.ErrFeedNotFound
+	}
+
+	return s.feeds[i], nil
+}
+
+// AddFeed assigns the feed a new ID and stores it. It will return domain.ErrFeedAlreadyExists if the URL is already registered
+func (s *fileFeedStore) AddFeed(feed domain.Subscription) (domain.Subscription, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.indexOfURL(feed.URL, "") >= 0 {
+		return domain.Subscription{}, domain.ErrFeedAlreadyExists
+	}
+
+	id, err := newID()
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	feed.ID = id
+
+	if err := s.persist(append(s.feeds, feed)); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return feed, nil
+}
+
+// UpdateFeed overwrites the subscription with the same ID
+func (s *fileFeedStore) UpdateFeed(feed domain.Subscription) (domain.Subscription, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i := s.indexOf(feed.ID)
+	if i < 0 {
+		return domain.Subscription{}, domain.ErrFeedNotFound
+	}
+
+	if s.indexOfURL(feed.URL, feed.ID) >= 0 {
+		return domain.Subscription{}, domain.ErrFeedAlreadyExists
+	}
+
+	feeds := make([]domain.Subscription, len(s.feeds))
+	copy(feeds, s.feeds)
+	feeds[i] = feed
+
+	if err := s.persist(feeds); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return feed, nil
+}
+
+// RemoveFeed deletes the subscription with the given id
+func (s *fileFeedStore) RemoveFeed(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return domain.ErrFeedNotFound
+	}
+
+	feeds := make([]domain.Subscription, 0, len(s.feeds)-1)
+	feeds = append(feeds, s.feeds[:i]...)
+	feeds = append(feeds, s.feeds[i+1:]...)
+
+	return s.persist(feeds)
+}
+
+// Ping reports whether the directory the store is persisted to is still there, subscriptions cannot be changed without it
+func (s *fileFeedStore) Ping(context.Context) error {
+	info, err := os.Stat(filepath.Dir(s.path))
+	if err != nil {
+		return fmt.Errorf("failed to find feed store directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("feed store directory %s is not a directory", filepath.Dir(s.path))
+	}
+
+	return nil
+}
+
+func (s *fileFeedStore) indexOf(id string) int {
+	for i, feed := range s.feeds {
+		if feed.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// indexOfURL finds a subscription with the given url, ignoring the subscription with the ID exclude
+func (s *fileFeedStore) indexOfURL(url, exclude string) int {
+	for i, feed := range s.feeds {
+		if feed.URL == url && feed.ID != exclude {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// persist writes feeds to disk and only replaces the in memory copy once the write has succeeded.
+// The file is written to a temporary location first so a failed write never corrupts the store.
+func (s *fileFeedStore) persist(feeds []domain.Subscription) error {
+	body, err := json.MarshalIndent(feeds, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to encode feed store: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write feed store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write feed store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write feed store: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write feed store: %w", err)
+	}
+
+	s.feeds = feeds
+
+	return nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate feed id: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"news-app/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fileFeedStore(t *testing.T) {
+	const (
+		someURL      = "https://some-url.com"
+		someOtherURL = "https://some-other-url.com"
+		someTitle    = "some-title"
+		someCategory = "some-category"
+	)
+	var someFeed = domain.Subscription{
+		URL:      someURL,
+		Title:    someTitle,
+		Category: someCategory,
+	}
+
+	t.Run("should add a feed and assign it an id", func(t *testing.T) {
+		store, err := NewFileFeedStore(filepath.Join(t.TempDir(), "feeds.json"))
+		require.NoError(t, err)
+
+		feed, err := store.AddFeed(someFeed)
+		require.NoError(t, err)
+		assert.NotEmpty(t, feed.ID)
+
+		got, err := store.GetFeed(feed.ID)
+		require.NoError(t, err)
+		assert.Equal(t, feed, got)
+	})
+	t.Run("should return an error when adding a url twice", func(t *testing.T) {
+		store, err := NewFileFeedStore(filepath.Join(t.TempDir(), "feeds.json"))
+		require.NoError(t, err)
+
+		_, err = store.AddFeed(someFeed)
+		require.NoError(t, err)
+
+		_, err = store.AddFeed(someFeed)
+		assert.ErrorIs(t, err, domain.ErrFeedAlreadyExists)
+	})
+	t.Run("should load feeds persisted by a previous store", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "feeds.json")
+		store, err := NewFileFeedStore(path)
+		require.NoError(t, err)
+
+		feed, err := store.AddFeed(someFeed)
+		require.NoError(t, err)
+
+		reopened, err := NewFileFeedStore(path)
+		require.NoError(t, err)
+
+		feeds, err := reopened.ListFeeds()
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Subscription{feed}, feeds)
+	})
+	t.Run("should update a feed", func(t *testing.T) {
+		store, err := NewFileFeedStore(filepath.Join(t.TempDir(), "feeds.json"))
+		require.NoError(t, err)
+
+		feed, err := store.AddFeed(someFeed)
+		require.NoError(t, err)
+
+		feed.URL = someOtherURL
+		updated, err := store.UpdateFeed(feed)
+		require.NoError(t, err)
+		assert.Equal(t, feed, updated)
+
+		got, err := store.GetFeed(feed.ID)
+		require.NoError(t, err)
+		assert.Equal(t, someOtherURL, got.URL)
+	})
+	t.Run("should return an error when updating to a url that is already registered", func(t *testing.T) {
+		store, err := NewFileFeedStore(filepath.Join(t.TempDir(), "feeds.json"))
+		require.NoError(t, err)
+
+		_, err = store.AddFeed(someFeed)
+		require.NoError(t, err)
+		other, err := store.AddFeed(domain.Subscription{URL: someOtherURL})
+		require.NoError(t, err)
+
+		other.URL = someURL
+		_, err = store.UpdateFeed(other)
+		assert.ErrorIs(t, err, domain.ErrFeedAlreadyExists)
+	})
+	t.Run("should remove a feed", func(t *testing.T) {
+		store, err := NewFileFeedStore(filepath.Join(t.TempDir(), "feeds.json"))
+		require.NoError(t, err)
+
+		feed, err := store.AddFeed(someFeed)
+		require.NoError(t, err)
+
+		err = store.RemoveFeed(feed.ID)
+		require.NoError(t, err)
+
+		_, err = store.GetFeed(feed.ID)
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+	})
+	t.Run("should return not found for unknown ids", func(t *testing.T) {
+		store, err := NewFileFeedStore(filepath.Join(t.TempDir(), "feeds.json"))
+		require.NoError(t, err)
+
+		_, err = store.GetFeed("unknown")
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+
+		_, err = store.UpdateFeed(domain.Subscription{ID: "unknown"})
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+
+		err = store.RemoveFeed("unknown")
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+	})
+	t.Run("should report whether the store directory exists", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "data")
+		require.NoError(t, os.Mkdir(dir, 0700))
+		store, err := NewFileFeedStore(filepath.Join(dir, "feeds.json"))
+		require.NoError(t, err)
+		assert.NoError(t, store.Ping(context.Background()))
+
+		require.NoError(t, os.Remove(dir))
+		assert.Error(t, store.Ping(context.Background()))
+	})
+}
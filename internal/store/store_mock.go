@@ -0,0 +1,232 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: news-app/internal/store (interfaces: FeedStore,ArticleStore)
+
+// Package store is a generated GoMock package.
+package store
+
+import (
+	context "context"
+	domain "news-app/internal/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFeedStore is a mock of FeedStore interface.
+type MockFeedStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedStoreMockRecorder
+}
+
+// MockFeedStoreMockRecorder is the mock recorder for MockFeedStore.
+type MockFeedStoreMockRecorder struct {
+	mock *MockFeedStore
+}
+
+// NewMockFeedStore creates a new mock instance.
+func NewMockFeedStore(ctrl *gomock.Controller) *MockFeedStore {
+	mock := &MockFeedStore{ctrl: ctrl}
+	mock.recorder = &MockFeedStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedStore) EXPECT() *MockFeedStoreMockRecorder {
+	return m.recorder
+}
+
+// AddFeed mocks base method.
+func (m *MockFeedStore) AddFeed(arg0 domain.Subscription) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFeed", arg0)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddFeed indicates an expected call of AddFeed.
+func (mr *MockFeedStoreMockRecorder) AddFeed(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFeed", reflect.TypeOf((*MockFeedStore)(nil).AddFeed), arg0)
+}
+
+// GetFeed mocks base method.
+func (m *MockFeedStore) GetFeed(arg0 string) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeed", arg0)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeed indicates an expected call of GetFeed.
+func (mr *MockFeedStoreMockRecorder) GetFeed(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeed", reflect.TypeOf((*MockFeedStore)(nil).GetFeed), arg0)
+}
+
+// ListFeeds mocks base method.
+func (m *MockFeedStore) ListFeeds() ([]domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeeds")
+	ret0, _ := ret[0].([]domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeeds indicates an expected call of ListFeeds.
+func (mr *MockFeedStoreMockRecorder) ListFeeds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeeds", reflect.TypeOf((*MockFeedStore)(nil).ListFeeds))
+}
+
+// Ping mocks base method.
+func (m *MockFeedStore) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockFeedStoreMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockFeedStore)(nil).Ping), arg0)
+}
+
+// RemoveFeed mocks base method.
+func (m *MockFeedStore) RemoveFeed(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFeed", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFeed indicates an expected call of RemoveFeed.
+func (mr *MockFeedStoreMockRecorder) RemoveFeed(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFeed", reflect.TypeOf((*MockFeedStore)(nil).RemoveFeed), arg0)
+}
+
+// UpdateFeed mocks base method.
+func (m *MockFeedStore) UpdateFeed(arg0 domain.Subscription) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFeed", arg0)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFeed indicates an expected call of UpdateFeed.
+func (mr *MockFeedStoreMockRecorder) UpdateFeed(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFeed", reflect.TypeOf((*MockFeedStore)(nil).UpdateFeed), arg0)
+}
+
+// MockArticleStore is a mock of ArticleStore interface.
+type MockArticleStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleStoreMockRecorder
+}
+
+// MockArticleStoreMockRecorder is the mock recorder for MockArticleStore.
+type MockArticleStoreMockRecorder struct {
+	mock *MockArticleStore
+}
+
+// NewMockArticleStore creates a new mock instance.
+func NewMockArticleStore(ctrl *gomock.Controller) *MockArticleStore {
+	mock := &MockArticleStore{ctrl: ctrl}
+	mock.recorder = &MockArticleStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleStore) EXPECT() *MockArticleStoreMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockArticleStore) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockArticleStoreMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockArticleStore)(nil).Close))
+}
+
+// ForEachFeed mocks base method.
+func (m *MockArticleStore) ForEachFeed(arg0 func(string, []domain.Article) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachFeed", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachFeed indicates an expected call of ForEachFeed.
+func (mr *MockArticleStoreMockRecorder) ForEachFeed(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachFeed", reflect.TypeOf((*MockArticleStore)(nil).ForEachFeed), arg0)
+}
+
+// GetArticles mocks base method.
+func (m *MockArticleStore) GetArticles(arg0 string) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticles", arg0)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArticles indicates an expected call of GetArticles.
+func (mr *MockArticleStoreMockRecorder) GetArticles(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticles", reflect.TypeOf((*MockArticleStore)(nil).GetArticles), arg0)
+}
+
+// MoveArticles mocks base method.
+func (m *MockArticleStore) MoveArticles(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveArticles", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveArticles indicates an expected call of MoveArticles.
+func (mr *MockArticleStoreMockRecorder) MoveArticles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveArticles", reflect.TypeOf((*MockArticleStore)(nil).MoveArticles), arg0, arg1)
+}
+
+// Ping mocks base method.
+func (m *MockArticleStore) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockArticleStoreMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockArticleStore)(nil).Ping), arg0)
+}
+
+// UpsertArticles mocks base method.
+func (m *MockArticleStore) UpsertArticles(arg0 string, arg1 []domain.Article) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertArticles", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertArticles indicates an expected call of UpsertArticles.
+func (mr *MockArticleStoreMockRecorder) UpsertArticles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertArticles", reflect.TypeOf((*MockArticleStore)(nil).UpsertArticles), arg0, arg1)
+}
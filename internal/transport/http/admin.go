@@ -0,0 +1,59 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// requireAdmin rejects requests that do not carry the admin token as a bearer token
+func (h handler) requireAdmin(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			h.writeErrorResponse(w, r, errUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h handler) ListCacheEntries(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.service.ListCacheEntries(r.Context())
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	h.writeSuccessResponse(w, entries)
+}
+
+// InvalidateFeed removes a feed from the cache, the feed is a registered feed ID or a path escaped feed URL
+func (h handler) InvalidateFeed(w http.ResponseWriter, r *http.Request) {
+	feed, err := url.PathUnescape(mux.Vars(r)["feed"])
+	if err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
+		return
+	}
+
+	if err := h.service.InvalidateFeed(r.Context(), feed); err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h handler) RefreshFeed(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.RefreshFeed(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+	"news-app/internal/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_handler_Admin(t *testing.T) {
+	const (
+		someToken   = "some-token"
+		someID      = "some-id"
+		someFeedURL = "https://some-feed-url/rss"
+	)
+	someEntries := []domain.CacheEntry{
+		{URL: someFeedURL, Created: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), AgeSeconds: 60, Size: 100},
+	}
+
+	setup := func(t *testing.T, token string) (*handler, *service.MockService) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, token)
+		handler.ApplyRoutes()
+
+		return handler, mockService
+	}
+
+	serve := func(handler *handler, method, path, token string) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	t.Run("should list cache entries", func(t *testing.T) {
+		handler, mockService := setup(t, someToken)
+
+		mockService.EXPECT().ListCacheEntries(gomock.Any()).Return(someEntries, nil)
+
+		res := serve(handler, http.MethodGet, adminCache, someToken)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var entries []domain.CacheEntry
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&entries))
+		assert.Equal(t, someEntries, entries)
+	})
+	t.Run("should invalidate a feed given an escaped feed url", func(t *testing.T) {
+		handler, mockService := setup(t, someToken)
+
+		mockService.EXPECT().InvalidateFeed(gomock.Any(), someFeedURL).Return(nil)
+
+		res := serve(handler, http.MethodDelete, "/admin/cache/"+url.PathEscape(someFeedURL), someToken)
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	})
+	t.Run("should return not found when invalidating a feed that is not cached", func(t *testing.T) {
+		handler, mockService := setup(t, someToken)
+
+		mockService.EXPECT().InvalidateFeed(gomock.Any(), someID).Return(domain.ErrNotCached)
+
+		res := serve(handler, http.MethodDelete, "/admin/cache/"+someID, someToken)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+	t.Run("should refresh a feed", func(t *testing.T) {
+		handler, mockService := setup(t, someToken)
+
+		mockService.EXPECT().RefreshFeed(gomock.Any(), someID).Return(nil)
+
+		res := serve(handler, http.MethodPost, "/admin/feeds/"+someID+"/refresh", someToken)
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	})
+	t.Run("should return not found when refreshing a feed that is not registered", func(t *testing.T) {
+		handler, mockService := setup(t, someToken)
+
+		mockService.EXPECT().RefreshFeed(gomock.Any(), someID).Return(domain.ErrFeedNotFound)
+
+		res := serve(handler, http.MethodPost, "/admin/feeds/"+someID+"/refresh", someToken)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+	t.Run("should return unauthorized without the admin token", func(t *testing.T) {
+		handler, _ := setup(t, someToken)
+
+		res := serve(handler, http.MethodGet, adminCache, "")
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		assert.Equal(t, "Bearer", res.Header.Get("WWW-Authenticate"))
+
+		res = serve(handler, http.MethodGet, adminCache, "some-other-token")
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+	t.Run("should not serve admin routes without an admin token configured", func(t *testing.T) {
+		handler, _ := setup(t, "")
+
+		res := serve(handler, http.MethodGet, adminCache, "")
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
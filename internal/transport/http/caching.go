@@ -0,0 +1,95 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"news-app/internal/domain"
+)
+
+// writeCacheableResponse writes a page of articles with validators so clients can revalidate it instead of downloading it again.
+// Conditional GET requests whose validators still match are answered with 304 Not Modified and no body.
+// The ETag is computed from the body so it changes whenever any article or the next cursor does.
+func (h handler) writeCacheableResponse(w http.ResponseWriter, r *http.Request, page domain.ArticlePage, i interface{}) {
+	body, _ := json.Marshal(i)
+
+	// POST responses cannot be reused by caches so are not worth validating
+	if r.Method != http.MethodGet {
+		h.writeBody(w, http.StatusOK, body)
+		return
+	}
+
+	etag := strongETag(body)
+	lastModified := newestPublished(page.Articles)
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl(page.MaxAge))
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.writeBody(w, http.StatusOK, body)
+}
+
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// newestPublished is the time the most recent article was published, it is zero if no article has a published time
+func newestPublished(articles []domain.Article) time.Time {
+	var newest time.Time
+	for _, a := range articles {
+		if a.Published.After(newest) {
+			newest = a.Published
+		}
+	}
+
+	return newest
+}
+
+// cacheControl lets clients reuse a response until the cache entry it came from expires,
+// anything else, such as stale articles or timelines across feeds, must be revalidated first
+func cacheControl(maxAge time.Duration) string {
+	seconds := int(maxAge / time.Second)
+	if seconds <= 0 {
+		return "no-cache"
+	}
+
+	return "max-age=" + strconv.Itoa(seconds)
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when there is no If-None-Match, against the response's validators
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			// If-None-Match uses the weak comparison so W/ is ignored
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince := r.Header.Get("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return false
+		}
+		// http dates only have second precision
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
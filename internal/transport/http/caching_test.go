@@ -0,0 +1,115 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+	"news-app/internal/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_handler_writeCacheableResponse(t *testing.T) {
+	const someFeedURL = "https://some-feed-url"
+	var (
+		someNewest   = time.Date(2022, 1, 2, 10, 30, 15, 500, time.UTC)
+		someArticles = []domain.Article{
+			{ID: "some-newest-id", Title: "some-title", Published: someNewest},
+			{ID: "some-oldest-id", Title: "some-other-title", Published: someNewest.Add(-time.Hour)},
+		}
+		somePage = domain.ArticlePage{Articles: someArticles, MaxAge: 90 * time.Second}
+	)
+
+	serve := func(t *testing.T, page domain.ArticlePage, method string, headers map[string]string) *httptest.ResponseRecorder {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+		handler.ApplyRoutes()
+
+		mockService.EXPECT().GetArticles(gomock.Any(), someFeedURL, gomock.Any()).Return(page, nil)
+
+		req, err := http.NewRequest(method, getArticlesByFeed+"?url="+someFeedURL, strings.NewReader(`{"feed_url":"`+someFeedURL+`"}`))
+		require.NoError(t, err)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should set validators and a max age from the cache entry", func(t *testing.T) {
+		w := serve(t, somePage, http.MethodGet, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Regexp(t, `^"[0-9a-f]{32}"$`, w.Header().Get("ETag"))
+		assert.Equal(t, "max-age=90", w.Header().Get("Cache-Control"))
+		assert.Equal(t, "Sun, 02 Jan 2022 10:30:15 GMT", w.Header().Get("Last-Modified"))
+	})
+
+	t.Run("should change the etag when the articles change", func(t *testing.T) {
+		changed := somePage
+		changed.Articles = []domain.Article{{ID: "some-newest-id", Title: "some-updated-title", Published: someNewest}}
+
+		assert.NotEqual(t, serve(t, somePage, http.MethodGet, nil).Header().Get("ETag"), serve(t, changed, http.MethodGet, nil).Header().Get("ETag"))
+		assert.Equal(t, serve(t, somePage, http.MethodGet, nil).Header().Get("ETag"), serve(t, somePage, http.MethodGet, nil).Header().Get("ETag"))
+	})
+
+	t.Run("should require revalidation of stale articles", func(t *testing.T) {
+		stale := somePage
+		stale.Stale = true
+		stale.MaxAge = 0
+
+		w := serve(t, stale, http.MethodGet, nil)
+		assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("should answer a matching If-None-Match with not modified", func(t *testing.T) {
+		etag := serve(t, somePage, http.MethodGet, nil).Header().Get("ETag")
+
+		for _, ifNoneMatch := range []string{etag, "W/" + etag, `"some-other-etag", ` + etag, "*"} {
+			w := serve(t, somePage, http.MethodGet, map[string]string{"If-None-Match": ifNoneMatch})
+
+			assert.Equal(t, http.StatusNotModified, w.Code, ifNoneMatch)
+			assert.Empty(t, w.Body.String())
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			assert.Equal(t, "max-age=90", w.Header().Get("Cache-Control"))
+		}
+	})
+
+	t.Run("should ignore If-Modified-Since when If-None-Match does not match", func(t *testing.T) {
+		w := serve(t, somePage, http.MethodGet, map[string]string{
+			"If-None-Match":     `"some-other-etag"`,
+			"If-Modified-Since": "Sun, 02 Jan 2022 10:30:15 GMT",
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Body.String())
+	})
+
+	t.Run("should answer If-Modified-Since with not modified unless a newer article was published", func(t *testing.T) {
+		w := serve(t, somePage, http.MethodGet, map[string]string{"If-Modified-Since": "Sun, 02 Jan 2022 10:30:15 GMT"})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+
+		w = serve(t, somePage, http.MethodGet, map[string]string{"If-Modified-Since": "Sun, 02 Jan 2022 10:30:14 GMT"})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = serve(t, somePage, http.MethodGet, map[string]string{"If-Modified-Since": "not a date"})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should not validate post responses", func(t *testing.T) {
+		w := serve(t, somePage, http.MethodPost, map[string]string{"If-Modified-Since": "Sun, 02 Jan 2022 10:30:15 GMT"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("ETag"))
+		assert.Empty(t, w.Header().Get("Last-Modified"))
+	})
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"news-app/internal/domain"
+	"news-app/internal/service"
+)
+
+func Test_Compress(t *testing.T) {
+	const minSize = 64
+	someLargeBody := strings.Repeat(`{"title":"some-title"}`, 20)
+	someSmallBody := `{"title":"some-title"}`
+
+	serve := func(t *testing.T, acceptEncoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		router := mux.NewRouter()
+		router.HandleFunc("/", handler)
+		router.Use(Compress(minSize))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	writeBody := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", `"some-etag"`)
+			// write in two parts so the threshold is crossed part way through
+			_, _ = io.WriteString(w, body[:len(body)/2])
+			_, _ = io.WriteString(w, body[len(body)/2:])
+		}
+	}
+
+	t.Run("should compress large responses with the encoding the client accepts", func(t *testing.T) {
+		w := serve(t, "gzip", writeBody(someLargeBody))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Equal(t, `W/"some-etag"`, w.Header().Get("ETag"))
+
+		reader, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, someLargeBody, string(body))
+
+		w = serve(t, "gzip, deflate, br", writeBody(someLargeBody))
+
+		assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+		body, err = io.ReadAll(brotli.NewReader(w.Body))
+		require.NoError(t, err)
+		assert.Equal(t, someLargeBody, string(body))
+	})
+
+	t.Run("should not compress small responses", func(t *testing.T) {
+		w := serve(t, "gzip", writeBody(someSmallBody))
+
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Equal(t, `W/"some-etag"`, w.Header().Get("ETag"))
+		assert.Equal(t, someSmallBody, w.Body.String())
+	})
+
+	t.Run("should not compress or weaken etags for clients that do not accept compression", func(t *testing.T) {
+		for _, acceptEncoding := range []string{"", "identity", "gzip;q=0, br;q=0", "deflate"} {
+			w := serve(t, acceptEncoding, writeBody(someLargeBody))
+
+			assert.Empty(t, w.Header().Get("Content-Encoding"), acceptEncoding)
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"), acceptEncoding)
+			assert.Equal(t, `"some-etag"`, w.Header().Get("ETag"), acceptEncoding)
+			assert.Equal(t, someLargeBody, w.Body.String(), acceptEncoding)
+		}
+	})
+
+	t.Run("should not compress responses without a body or that are already encoded", func(t *testing.T) {
+		w := serve(t, "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotModified)
+		})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+
+		w = serve(t, "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "some-encoding")
+			_, _ = io.WriteString(w, someLargeBody)
+		})
+		assert.Equal(t, "some-encoding", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, someLargeBody, w.Body.String())
+	})
+
+	t.Run("should keep the status code of compressed responses", func(t *testing.T) {
+		w := serve(t, "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = io.WriteString(w, someLargeBody)
+		})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("should still answer conditional requests with the weakened etag", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+		handler.ApplyRoutes()
+		handler.Use(Compress(minSize))
+
+		someArticles := []domain.Article{{ID: "some-id", Title: strings.Repeat("some-title", 20)}}
+		mockService.EXPECT().GetArticles(gomock.Any(), "https://some-feed-url", gomock.Any()).Return(domain.ArticlePage{Articles: someArticles}, nil).Times(2)
+
+		req := httptest.NewRequest(http.MethodGet, getArticlesByFeed+"?url=https://some-feed-url", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		etag := w.Header().Get("ETag")
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.True(t, strings.HasPrefix(etag, "W/"))
+
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+	})
+}
+
+func Test_negotiateEncoding(t *testing.T) {
+	t.Run("should pick the encoding the client prefers", func(t *testing.T) {
+		for acceptEncoding, expected := range map[string]string{
+			"":                      "",
+			"gzip":                  "gzip",
+			"GZIP":                  "gzip",
+			"br":                    "br",
+			"gzip, br":              "br",
+			"br;q=0.5, gzip":        "gzip",
+			"gzip;q=0.8, br;q=0.9":  "br",
+			"br;q=0, gzip;q=0":      "",
+			"*":                     "br",
+			"*;q=0.1, gzip;q=0.5":   "gzip",
+			"deflate, identity":     "",
+			"gzip;q=invalid, br;q=": "",
+		} {
+			assert.Equal(t, expected, negotiateEncoding(acceptEncoding), acceptEncoding)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"news-app/internal/domain"
+)
+
+var (
+	// errInvalidRequest is returned when a request fails to decode or validate
+	errInvalidRequest = errors.New("invalid request")
+	// errUnauthorized is returned when an admin request does not carry the admin token
+	errUnauthorized = errors.New("invalid admin token")
+)
+
+// invalidRequest marks an error decoding or validating a request so it is reported as a bad request
+func invalidRequest(err error) error {
+	return fmt.Errorf("%w: %v", errInvalidRequest, err)
+}
+
+// errorMappings maps errors to the status and machine readable code they are reported with, the first match wins
+var errorMappings = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{errInvalidRequest, http.StatusBadRequest, "invalid_request"},
+	{errUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{domain.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	{domain.ErrInvalidQuery, http.StatusBadRequest, "invalid_query"},
+	{domain.ErrInvalidFeedURL, http.StatusBadRequest, "invalid_feed_url"},
+	{domain.ErrBlockedURL, http.StatusForbidden, "feed_url_blocked"},
+	{domain.ErrFeedNotFound, http.StatusNotFound, "feed_not_found"},
+	{domain.ErrNotCached, http.StatusNotFound, "feed_not_cached"},
+	{domain.ErrFeedAlreadyExists, http.StatusConflict, "feed_already_exists"},
+	{domain.ErrFeedUnreachable, http.StatusBadGateway, "feed_unreachable"},
+	{domain.ErrUpstreamStatus, http.StatusBadGateway, "feed_upstream_status"},
+	{domain.ErrFeedTooLarge, http.StatusBadGateway, "feed_too_large"},
+	{domain.ErrFeedTimeout, http.StatusGatewayTimeout, "feed_timeout"},
+	{domain.ErrHostThrottled, http.StatusServiceUnavailable, "feed_host_throttled"},
+	{domain.ErrCircuitOpen, http.StatusServiceUnavailable, "feed_circuit_open"},
+	{domain.ErrMalformedFeed, http.StatusUnprocessableEntity, "feed_malformed"},
+	{domain.ErrUnsupportedFormat, http.StatusUnprocessableEntity, "feed_unsupported_format"},
+}
+
+// errorBody is the body of every error response
+type errorBody struct {
+	Error string `json:"error"`
+	// Code identifies the kind of error so clients do not have to match on the message
+	Code string `json:"code"`
+	// UpstreamStatus is the status the publisher responded with when the code is feed_upstream_status
+	UpstreamStatus int `json:"upstream_status,omitempty"`
+}
+
+// errorResponse returns the status and body err is reported with, anything unrecognised is our fault and an internal error
+func errorResponse(err error) (int, errorBody) {
+	body := errorBody{
+		Error: err.Error(),
+		Code:  "internal_error",
+	}
+
+	var fetchErr *domain.FetchError
+	if errors.As(err, &fetchErr) {
+		body.UpstreamStatus = fetchErr.StatusCode
+	}
+
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			body.Code = m.code
+			return m.status, body
+		}
+	}
+
+	return http.StatusInternalServerError, body
+}
+
+// retryAfterSeconds returns how many whole seconds the client should wait before trying again, zero if err gives no advice
+func retryAfterSeconds(err error) int {
+	var fetchErr *domain.FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.RetryAfter <= 0 {
+		return 0
+	}
+
+	return int(math.Ceil(fetchErr.RetryAfter.Seconds()))
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+	"news-app/internal/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_errorResponse(t *testing.T) {
+	t.Run("should map errors to a status and code however deeply they are wrapped", func(t *testing.T) {
+		for _, tc := range []struct {
+			err    error
+			status int
+			code   string
+		}{
+			{invalidRequest(errors.New("some validation error")), http.StatusBadRequest, "invalid_request"},
+			{errUnauthorized, http.StatusUnauthorized, "unauthorized"},
+			{fmt.Errorf("failed to get feed: %w", domain.ErrFeedNotFound), http.StatusNotFound, "feed_not_found"},
+			{fmt.Errorf("failed to add feed: %w", domain.ErrFeedAlreadyExists), http.StatusConflict, "feed_already_exists"},
+			{domain.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+			{fmt.Errorf("failed to parse feed: %w", &domain.FetchError{Kind: domain.ErrFeedTimeout, Err: context.DeadlineExceeded}), http.StatusGatewayTimeout, "feed_timeout"},
+			{&domain.FetchError{Kind: domain.ErrFeedUnreachable, Err: errors.New("connection refused")}, http.StatusBadGateway, "feed_unreachable"},
+			{&domain.FetchError{Kind: domain.ErrMalformedFeed, Err: errors.New("unexpected EOF")}, http.StatusUnprocessableEntity, "feed_malformed"},
+			{&domain.FetchError{Kind: domain.ErrUnsupportedFormat}, http.StatusUnprocessableEntity, "feed_unsupported_format"},
+			{&domain.FetchError{Kind: domain.ErrFeedTooLarge}, http.StatusBadGateway, "feed_too_large"},
+			{&domain.FetchError{Kind: domain.ErrHostThrottled, RetryAfter: time.Second}, http.StatusServiceUnavailable, "feed_host_throttled"},
+			{&domain.FetchError{Kind: domain.ErrCircuitOpen, RetryAfter: time.Minute}, http.StatusServiceUnavailable, "feed_circuit_open"},
+			{&domain.FetchError{Kind: domain.ErrBlockedURL}, http.StatusForbidden, "feed_url_blocked"},
+			{&domain.FetchError{Kind: domain.ErrInvalidFeedURL}, http.StatusBadRequest, "invalid_feed_url"},
+			{assert.AnError, http.StatusInternalServerError, "internal_error"},
+		} {
+			status, body := errorResponse(tc.err)
+
+			assert.Equal(t, tc.status, status, tc.err.Error())
+			assert.Equal(t, tc.code, body.Code, tc.err.Error())
+			assert.Equal(t, tc.err.Error(), body.Error)
+		}
+	})
+}
+
+func Test_handler_writeErrorResponse(t *testing.T) {
+	t.Run("should tell clients when to retry a feed whose host is throttled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+		handler.ApplyRoutes()
+
+		mockService.EXPECT().GetArticles(gomock.Any(), "https://some-feed-url", gomock.Any()).Return(domain.ArticlePage{}, fmt.Errorf("failed to parse feed: %w", &domain.FetchError{
+			Kind:       domain.ErrHostThrottled,
+			RetryAfter: 1500 * time.Millisecond,
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, getArticlesByFeed+"?url=https://some-feed-url", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	})
+	t.Run("should report the upstream status of a feed that returned an error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+		handler.ApplyRoutes()
+
+		mockService.EXPECT().GetArticles(gomock.Any(), "https://some-feed-url", gomock.Any()).Return(domain.ArticlePage{}, fmt.Errorf("failed to parse feed: %w", &domain.FetchError{
+			Kind:       domain.ErrUpstreamStatus,
+			StatusCode: http.StatusNotFound,
+			Err:        errors.New("404 Not Found"),
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, getArticlesByFeed+"?url=https://some-feed-url", nil))
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, map[string]interface{}{
+			"error":           "failed to parse feed: feed returned an error status: 404 Not Found",
+			"code":            "feed_upstream_status",
+			"upstream_status": float64(http.StatusNotFound),
+		}, body)
+	})
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"news-app/internal/domain"
+	"news-app/internal/logging"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type addFeedRequest struct {
+	URL                    string `json:"url" validate:"required,url"`
+	Title                  string `json:"title"`
+	Category               string `json:"category"`
+	RefreshIntervalSeconds int    `json:"refresh_interval_seconds" validate:"min=0"`
+}
+
+type updateFeedRequest struct {
+	URL                    *string `json:"url" validate:"omitempty,url"`
+	Title                  *string `json:"title"`
+	Category               *string `json:"category"`
+	RefreshIntervalSeconds *int    `json:"refresh_interval_seconds" validate:"omitempty,min=0"`
+}
+
+func (h handler) ListFeeds(w http.ResponseWriter, r *http.Request) {
+	feeds, err := h.service.ListFeeds(r.Context())
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	h.writeSuccessResponse(w, feeds)
+}
+
+func (h handler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	feed, err := h.service.GetFeed(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	h.writeSuccessResponse(w, feed)
+}
+
+func (h handler) AddFeed(w http.ResponseWriter, r *http.Request) {
+	var request addFeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := validator.New().Struct(request); err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
+		return
+	}
+
+	feed, err := h.service.AddFeed(r.Context(), domain.Subscription{
+		URL:                    request.URL,
+		Title:                  request.Title,
+		Category:               request.Category,
+		RefreshIntervalSeconds: request.RefreshIntervalSeconds,
+	})
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	h.writeResponse(w, http.StatusCreated, feed)
+}
+
+func (h handler) UpdateFeed(w http.ResponseWriter, r *http.Request) {
+	var request updateFeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := validator.New().Struct(request); err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
+		return
+	}
+
+	feed, err := h.service.UpdateFeed(r.Context(), mux.Vars(r)["id"], domain.SubscriptionUpdate{
+		URL:                    request.URL,
+		Title:                  request.Title,
+		Category:               request.Category,
+		RefreshIntervalSeconds: request.RefreshIntervalSeconds,
+	})
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	h.writeSuccessResponse(w, feed)
+}
+
+func (h handler) RemoveFeed(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.RemoveFeed(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h handler) GetArticlesByFeedID(w http.ResponseWriter, r *http.Request) {
+	feed, err := h.service.GetFeed(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	page, err := h.readPageRequest(r)
+	if err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
+		return
+	}
+
+	logging.AddRequestFields(r.Context(), "feed_id", feed.ID, "feed_url", feed.URL)
+	articles, err := h.service.GetArticles(r.Context(), feed.URL, page)
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	h.writeStaleHeaders(w, articles.Stale)
+	h.writeCacheableResponse(w, r, articles, articles)
+}
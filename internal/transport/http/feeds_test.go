@@ -0,0 +1,196 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"news-app/internal/domain"
+	"news-app/internal/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_handler_Feeds(t *testing.T) {
+	var (
+		someID       = "some-id"
+		someFeedURL  = "https://some-feed-url"
+		someTitle    = "some-title"
+		someCategory = "some-category"
+		someFeed     = domain.Subscription{
+			ID:       someID,
+			URL:      someFeedURL,
+			Title:    someTitle,
+			Category: someCategory,
+		}
+		someArticles = []domain.Article{
+			{
+				Title: someTitle,
+			},
+		}
+	)
+
+	t.Run("should list feeds", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().ListFeeds(gomock.Any()).Return([]domain.Subscription{someFeed}, nil)
+
+		req, err := http.NewRequest(http.MethodGet, feeds, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.ListFeeds(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		bytes, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		var feeds []domain.Subscription
+		err = json.Unmarshal(bytes, &feeds)
+		require.NoError(t, err)
+
+		assert.Equal(t, []domain.Subscription{someFeed}, feeds)
+	})
+
+	t.Run("should create a feed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().AddFeed(gomock.Any(), domain.Subscription{
+			URL:      someFeedURL,
+			Title:    someTitle,
+			Category: someCategory,
+		}).Return(someFeed, nil)
+
+		body := []byte(`{"url":"https://some-feed-url","title":"some-title","category":"some-category"}`)
+		req, err := http.NewRequest(http.MethodPost, feeds, bytes.NewReader(body))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.AddFeed(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+	})
+
+	t.Run("should return a bad request if feed url is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		body := []byte(`{"url":"not-a-url"}`)
+		req, err := http.NewRequest(http.MethodPost, feeds, bytes.NewReader(body))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.AddFeed(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should return a conflict if feed already exists", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().AddFeed(gomock.Any(), gomock.Any()).Return(domain.Subscription{}, domain.ErrFeedAlreadyExists)
+
+		body := []byte(`{"url":"https://some-feed-url"}`)
+		req, err := http.NewRequest(http.MethodPost, feeds, bytes.NewReader(body))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.AddFeed(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+	})
+
+	t.Run("should update a feed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		title := someTitle
+		mockService.EXPECT().UpdateFeed(gomock.Any(), someID, domain.SubscriptionUpdate{Title: &title}).Return(someFeed, nil)
+
+		body := []byte(`{"title":"some-title"}`)
+		req, err := http.NewRequest(http.MethodPatch, feedByID, bytes.NewReader(body))
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": someID})
+
+		w := httptest.NewRecorder()
+		handler.UpdateFeed(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("should return not found if feed does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().GetFeed(gomock.Any(), someID).Return(domain.Subscription{}, domain.ErrFeedNotFound)
+
+		req, err := http.NewRequest(http.MethodGet, feedByID, nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": someID})
+
+		w := httptest.NewRecorder()
+		handler.GetFeed(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("should remove a feed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().RemoveFeed(gomock.Any(), someID).Return(nil)
+
+		req, err := http.NewRequest(http.MethodDelete, feedByID, nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": someID})
+
+		w := httptest.NewRecorder()
+		handler.RemoveFeed(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	})
+
+	t.Run("should return articles for a registered feed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().GetFeed(gomock.Any(), someID).Return(someFeed, nil)
+		mockService.EXPECT().GetArticles(gomock.Any(), someFeedURL, gomock.Any()).Return(domain.ArticlePage{Articles: someArticles}, nil)
+
+		req, err := http.NewRequest(http.MethodGet, getArticlesByFeedID, nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": someID})
+
+		w := httptest.NewRecorder()
+		handler.GetArticlesByFeedID(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+}
@@ -2,74 +2,195 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
+	"io"
 	"net/http"
+	"news-app/internal/domain"
+	"news-app/internal/logging"
 	"news-app/internal/service"
+	"strconv"
 
 	"github.com/go-playground/validator/v10"
 )
 
-const getArticlesByFeed = "/articles/feed"
+const (
+	getArticles         = "/articles"
+	getArticlesByFeed   = "/articles/feed"
+	feeds               = "/feeds"
+	feedByID            = "/feeds/{id}"
+	getArticlesByFeedID = "/feeds/{id}/articles"
+	searchArticles      = "/search"
+
+	adminCache       = "/admin/cache"
+	adminCacheByFeed = "/admin/cache/{feed}"
+	adminRefreshFeed = "/admin/feeds/{id}/refresh"
+)
+
+// defaultPageLimit is the number of articles returned when the client does not send a limit
+const defaultPageLimit = 50
+
+const (
+	// cacheStatusHeader tells clients when articles were served from an expired cache entry
+	cacheStatusHeader = "X-Cache-Status"
+	// staleWarning is the standard warning for a stale response
+	staleWarning = `110 - "Response is Stale"`
+)
 
 // handler is our internal representation of a http handler
 type handler struct {
-	service service.Service
+	service    service.Service
+	adminToken string
 	*mux.Router
 }
 
 // NewHandler is a constructor for a http handler
-func NewHandler(service service.Service) *handler {
+// adminToken is the bearer token required by admin routes, admin routes are not served when it is empty
+func NewHandler(service service.Service, adminToken string) *handler {
 	return &handler{
-		service: service,
-		Router:  mux.NewRouter(),
+		service:    service,
+		adminToken: adminToken,
+		// keep path variables escaped so feed URLs can be used as a single path segment
+		Router: mux.NewRouter().UseEncodedPath(),
 	}
 }
 
 func (h *handler) ApplyRoutes() {
-	h.HandleFunc(getArticlesByFeed, h.GetArticles).Methods(http.MethodGet)
+	h.HandleFunc(getArticles, h.GetTimeline).Methods(http.MethodGet)
+	h.HandleFunc(getArticlesByFeed, h.GetArticles).Methods(http.MethodGet, http.MethodPost)
+
+	h.HandleFunc(feeds, h.ListFeeds).Methods(http.MethodGet)
+	h.HandleFunc(feeds, h.AddFeed).Methods(http.MethodPost)
+	h.HandleFunc(feedByID, h.GetFeed).Methods(http.MethodGet)
+	h.HandleFunc(feedByID, h.UpdateFeed).Methods(http.MethodPatch)
+	h.HandleFunc(feedByID, h.RemoveFeed).Methods(http.MethodDelete)
+	h.HandleFunc(getArticlesByFeedID, h.GetArticlesByFeedID).Methods(http.MethodGet)
+
+	h.HandleFunc(searchArticles, h.Search).Methods(http.MethodGet)
+
+	if h.adminToken != "" {
+		h.Handle(adminCache, h.requireAdmin(h.ListCacheEntries)).Methods(http.MethodGet)
+		h.Handle(adminCacheByFeed, h.requireAdmin(h.InvalidateFeed)).Methods(http.MethodDelete)
+		h.Handle(adminRefreshFeed, h.requireAdmin(h.RefreshFeed)).Methods(http.MethodPost)
+	}
 }
 
 type getArticlesRequest struct {
 	FeedURL string `json:"feed_url" validate:"required"`
 }
 
+type pageRequest struct {
+	Limit  int `validate:"min=1,max=500"`
+	Cursor string
+}
+
+// GetArticles returns a page of articles for the feed given by the url query parameter, or by feed_url in a JSON body for POST requests.
+// A JSON body is still read on GET requests without the query parameter for older clients.
 func (h handler) GetArticles(w http.ResponseWriter, r *http.Request) {
-	var request getArticlesRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, err)
+	request, err := h.readGetArticlesRequest(r)
+	if err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
 		return
 	}
-	defer r.Body.Close()
 
-	if err := validator.New().Struct(request); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, err)
+	page, err := h.readPageRequest(r)
+	if err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
 		return
 	}
 
-	articles, err := h.service.GetArticles(r.Context(), request.FeedURL)
+	logging.AddRequestFields(r.Context(), "feed_url", request.FeedURL)
+	articles, err := h.service.GetArticles(r.Context(), request.FeedURL, page)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusInternalServerError, err)
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	h.writeStaleHeaders(w, articles.Stale)
+	h.writeCacheableResponse(w, r, articles, articles)
+}
+
+// readGetArticlesRequest reads the feed URL from the query, falling back to the body
+func (h handler) readGetArticlesRequest(r *http.Request) (getArticlesRequest, error) {
+	var request getArticlesRequest
+	if feedURL := r.URL.Query().Get("url"); feedURL != "" {
+		request.FeedURL = feedURL
+	} else {
+		defer r.Body.Close()
+
+		// an empty body is left to the validator to report the missing URL
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			return getArticlesRequest{}, err
+		}
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		return getArticlesRequest{}, err
+	}
+
+	return request, nil
+}
+
+// readPageRequest reads the limit and cursor query parameters used by every article listing
+func (h handler) readPageRequest(r *http.Request) (domain.PageRequest, error) {
+	query := r.URL.Query()
+
+	request := pageRequest{
+		Limit:  defaultPageLimit,
+		Cursor: query.Get("cursor"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if request.Limit, err = strconv.Atoi(limit); err != nil {
+			return domain.PageRequest{}, err
+		}
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		return domain.PageRequest{}, err
+	}
+
+	return domain.PageRequest{
+		Limit:  request.Limit,
+		Cursor: request.Cursor,
+	}, nil
+}
+
+// writeStaleHeaders marks a response as stale when its articles came from an expired cache entry
+func (h handler) writeStaleHeaders(w http.ResponseWriter, stale bool) {
+	if !stale {
 		return
 	}
 
-	h.writeSuccessResponse(w, articles)
+	w.Header().Set(cacheStatusHeader, "stale")
+	w.Header().Set("Warning", staleWarning)
 }
 
 func (h handler) writeSuccessResponse(w http.ResponseWriter, i interface{}) {
+	h.writeResponse(w, http.StatusOK, i)
+}
+
+func (h handler) writeResponse(w http.ResponseWriter, statusCode int, i interface{}) {
 	body, _ := json.Marshal(i)
+	h.writeBody(w, statusCode, body)
+}
+
+func (h handler) writeBody(w http.ResponseWriter, statusCode int, body []byte) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(statusCode)
 	_, _ = w.Write(body)
 }
 
-func (h handler) writeErrorResponse(w http.ResponseWriter, statusCode int, err error) {
-	response := struct {
-		ErrorString string `json:"error"`
-	}{
-		ErrorString: err.Error(),
-	}
+// writeErrorResponse writes err with the status and code it maps to, the error is added to the line logged for the request
+func (h handler) writeErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	logging.AddRequestFields(r.Context(), "error", err)
+
+	statusCode, response := errorResponse(err)
 	body, _ := json.Marshal(response)
 	w.Header().Set("Content-Type", "application/json")
+	if seconds := retryAfterSeconds(err); statusCode == http.StatusServiceUnavailable && seconds > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
 	w.WriteHeader(statusCode)
 	_, _ = w.Write(body)
 }
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"news-app/internal/domain"
@@ -39,9 +40,9 @@ func Test_handler_GetArticles(t *testing.T) {
 	t.Run("should return articles if service is successful", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockService := service.NewMockService(ctrl)
-		handler := NewHandler(mockService)
+		handler := NewHandler(mockService, "")
 
-		mockService.EXPECT().GetArticles(gomock.Any(), someFeedURL).Return(someArticles, nil)
+		mockService.EXPECT().GetArticles(gomock.Any(), someFeedURL, domain.PageRequest{Limit: defaultPageLimit}).Return(domain.ArticlePage{Articles: someArticles}, nil)
 
 		body := []byte(`{"feed_url":"https://some-feed-url"}`)
 		req, err := http.NewRequest(http.MethodGet, getArticlesByFeed, bytes.NewReader(body))
@@ -52,24 +53,45 @@ func Test_handler_GetArticles(t *testing.T) {
 
 		res := w.Result()
 		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Empty(t, res.Header.Get(cacheStatusHeader))
 
 		bytes, err := io.ReadAll(res.Body)
 		require.NoError(t, err)
 		defer res.Body.Close()
 
-		var articles []domain.Article
-		err = json.Unmarshal(bytes, &articles)
+		var page domain.ArticlePage
+		err = json.Unmarshal(bytes, &page)
 		require.NoError(t, err)
 
-		assert.Equal(t, someArticles, articles)
+		assert.Equal(t, someArticles, page.Articles)
+	})
+
+	t.Run("should mark the response as stale if the articles came from an expired cache entry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().GetArticles(gomock.Any(), someFeedURL, gomock.Any()).Return(domain.ArticlePage{Articles: someArticles, Stale: true}, nil)
+
+		body := []byte(`{"feed_url":"https://some-feed-url"}`)
+		req, err := http.NewRequest(http.MethodGet, getArticlesByFeed, bytes.NewReader(body))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.GetArticles(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "stale", res.Header.Get(cacheStatusHeader))
+		assert.Equal(t, staleWarning, res.Header.Get("Warning"))
 	})
 
 	t.Run("should return a internal server error if service layer fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockService := service.NewMockService(ctrl)
-		handler := NewHandler(mockService)
+		handler := NewHandler(mockService, "")
 
-		mockService.EXPECT().GetArticles(gomock.Any(), someFeedURL).Return([]domain.Article{}, assert.AnError)
+		mockService.EXPECT().GetArticles(gomock.Any(), someFeedURL, gomock.Any()).Return(domain.ArticlePage{}, assert.AnError)
 
 		body := []byte(`{"feed_url":"https://some-feed-url"}`)
 		req, err := http.NewRequest(http.MethodGet, getArticlesByFeed, bytes.NewReader(body))
@@ -85,7 +107,7 @@ func Test_handler_GetArticles(t *testing.T) {
 	t.Run("should return a bad request if json if request body is invalid", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockService := service.NewMockService(ctrl)
-		handler := NewHandler(mockService)
+		handler := NewHandler(mockService, "")
 
 		body := []byte(`{"invalid"}`)
 		req, err := http.NewRequest(http.MethodGet, getArticlesByFeed, bytes.NewReader(body))
@@ -101,7 +123,7 @@ func Test_handler_GetArticles(t *testing.T) {
 	t.Run("should return a bad request if url is missing from body", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockService := service.NewMockService(ctrl)
-		handler := NewHandler(mockService)
+		handler := NewHandler(mockService, "")
 
 		body := []byte(`{"other-data":"some-other-data"}`)
 		req, err := http.NewRequest(http.MethodGet, getArticlesByFeed, bytes.NewReader(body))
@@ -113,4 +135,137 @@ func Test_handler_GetArticles(t *testing.T) {
 		res := w.Result()
 		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
 	})
+
+	t.Run("should read the feed url from the query without a body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+		handler.ApplyRoutes()
+
+		mockService.EXPECT().GetArticles(gomock.Any(), someFeedURL, domain.PageRequest{Limit: 10}).Return(domain.ArticlePage{Articles: someArticles}, nil)
+
+		req, err := http.NewRequest(http.MethodGet, getArticlesByFeed+"?url="+url.QueryEscape(someFeedURL)+"&limit=10", nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should prefer the feed url in the query to the body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().GetArticles(gomock.Any(), someFeedURL, gomock.Any()).Return(domain.ArticlePage{Articles: someArticles}, nil)
+
+		body := []byte(`{"feed_url":"https://some-other-feed-url"}`)
+		req, err := http.NewRequest(http.MethodGet, getArticlesByFeed+"?url="+url.QueryEscape(someFeedURL), bytes.NewReader(body))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.GetArticles(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should read the feed url from the body of a post", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+		handler.ApplyRoutes()
+
+		mockService.EXPECT().GetArticles(gomock.Any(), someFeedURL, gomock.Any()).Return(domain.ArticlePage{Articles: someArticles}, nil)
+
+		body := []byte(`{"feed_url":"https://some-feed-url"}`)
+		req, err := http.NewRequest(http.MethodPost, getArticlesByFeed, bytes.NewReader(body))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should return a bad request if there is no query and no body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		for _, method := range []string{http.MethodGet, http.MethodPost} {
+			req, err := http.NewRequest(method, getArticlesByFeed, http.NoBody)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			handler.GetArticles(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, method)
+		}
+	})
+
+	t.Run("should pass the limit and cursor to the service and return the next cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().GetArticles(gomock.Any(), someFeedURL, domain.PageRequest{Limit: 1, Cursor: "some-cursor"}).Return(domain.ArticlePage{
+			Articles:   someArticles,
+			NextCursor: "some-next-cursor",
+		}, nil)
+
+		body := []byte(`{"feed_url":"https://some-feed-url"}`)
+		req, err := http.NewRequest(http.MethodGet, getArticlesByFeed+"?limit=1&cursor=some-cursor", bytes.NewReader(body))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.GetArticles(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		bytes, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		var page domain.ArticlePage
+		err = json.Unmarshal(bytes, &page)
+		require.NoError(t, err)
+
+		assert.Equal(t, "some-next-cursor", page.NextCursor)
+	})
+
+	t.Run("should return a bad request if limit is out of range", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		body := []byte(`{"feed_url":"https://some-feed-url"}`)
+		req, err := http.NewRequest(http.MethodGet, getArticlesByFeed+"?limit=1000", bytes.NewReader(body))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.GetArticles(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should return a bad request if cursor is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().GetArticles(gomock.Any(), someFeedURL, gomock.Any()).Return(domain.ArticlePage{}, domain.ErrInvalidCursor)
+
+		body := []byte(`{"feed_url":"https://some-feed-url"}`)
+		req, err := http.NewRequest(http.MethodGet, getArticlesByFeed+"?cursor=invalid", bytes.NewReader(body))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.GetArticles(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
 }
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"news-app/internal/domain"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// dateLayout is accepted by the from and to search parameters alongside RFC 3339 timestamps
+const dateLayout = "2006-01-02"
+
+type searchRequest struct {
+	Query string   `validate:"required"`
+	Feeds []string `validate:"dive,required"`
+}
+
+// Search returns articles matching the q query parameter, optionally filtered by feed and a from and to date
+func (h handler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	request := searchRequest{
+		Query: query.Get("q"),
+		Feeds: query["feed"],
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
+		return
+	}
+
+	from, err := parseSearchTime(query.Get("from"), false)
+	if err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
+		return
+	}
+	to, err := parseSearchTime(query.Get("to"), true)
+	if err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
+		return
+	}
+
+	page, err := h.readPageRequest(r)
+	if err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
+		return
+	}
+
+	results, err := h.service.Search(r.Context(), domain.SearchQuery{
+		Query: request.Query,
+		Feeds: request.Feeds,
+		From:  from,
+		To:    to,
+		Page:  page,
+	})
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	h.writeSuccessResponse(w, results)
+}
+
+// parseSearchTime accepts either an RFC 3339 timestamp or a date, when endOfDay is set a date covers the whole day
+func parseSearchTime(s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339 or %s", s, dateLayout)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+	"news-app/internal/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_handler_Search(t *testing.T) {
+	var (
+		someID      = "some-id"
+		someTitle   = "some-title"
+		someResults = domain.SearchResults{
+			Results: []domain.SearchResult{
+				{
+					Article: domain.Article{ID: someID, Title: someTitle},
+					Score:   1.5,
+				},
+			},
+			Total:      2,
+			NextCursor: "some-next-cursor",
+		}
+	)
+
+	t.Run("should return search results for the query and filters", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().Search(gomock.Any(), domain.SearchQuery{
+			Query: "energy prices",
+			Feeds: []string{someID},
+			From:  time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:    time.Date(2022, 1, 31, 23, 59, 59, 999999999, time.UTC),
+			Page:  domain.PageRequest{Limit: 1},
+		}).Return(someResults, nil)
+
+		req, err := http.NewRequest(http.MethodGet, searchArticles+"?q=energy+prices&feed=some-id&from=2022-01-01&to=2022-01-31&limit=1", nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.Search(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		bytes, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		var results domain.SearchResults
+		err = json.Unmarshal(bytes, &results)
+		require.NoError(t, err)
+
+		assert.Equal(t, someResults, results)
+	})
+
+	t.Run("should return a bad request if query is missing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		req, err := http.NewRequest(http.MethodGet, searchArticles, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.Search(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should return a bad request if a date is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		req, err := http.NewRequest(http.MethodGet, searchArticles+"?q=energy&from=yesterday", nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.Search(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should return a bad request if the query has no searchable terms", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().Search(gomock.Any(), gomock.Any()).Return(domain.SearchResults{}, domain.ErrInvalidQuery)
+
+		req, err := http.NewRequest(http.MethodGet, searchArticles+"?q=the", nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.Search(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
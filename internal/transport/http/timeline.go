@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+
+	"news-app/internal/domain"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// getTimelineRequest allows at most 50 feeds so one request cannot make us fetch any number of feeds
+type getTimelineRequest struct {
+	Feeds []string `validate:"max=50,dive,required"`
+}
+
+// GetTimeline returns articles merged from every feed given in the feed query parameter,
+// or every registered feed if none are given
+func (h handler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	request := getTimelineRequest{
+		Feeds: r.URL.Query()["feed"],
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
+		return
+	}
+
+	page, err := h.readPageRequest(r)
+	if err != nil {
+		h.writeErrorResponse(w, r, invalidRequest(err))
+		return
+	}
+
+	timeline, err := h.service.GetTimeline(r.Context(), request.Feeds, domain.TimelineOptions{
+		Page: page,
+	})
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	h.writeStaleHeaders(w, timeline.Stale)
+	h.writeCacheableResponse(w, r, timeline.ArticlePage, timeline)
+}
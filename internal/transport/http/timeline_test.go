@@ -0,0 +1,121 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"news-app/internal/domain"
+	"news-app/internal/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_handler_GetTimeline(t *testing.T) {
+	var (
+		someID       = "some-id"
+		someFeedURL  = "https://some-feed-url"
+		someTitle    = "some-title"
+		someTimeline = domain.Timeline{
+			ArticlePage: domain.ArticlePage{
+				Articles: []domain.Article{
+					{
+						Title:  someTitle,
+						Source: &domain.Source{FeedID: someID, URL: someFeedURL},
+					},
+				},
+				NextCursor: "some-next-cursor",
+			},
+			Errors: []domain.FeedError{
+				{
+					Source: "some-other-id",
+					Error:  "feed not found",
+				},
+			},
+		}
+	)
+
+	t.Run("should return a timeline for the requested feeds", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().GetTimeline(gomock.Any(), []string{someID, someFeedURL}, domain.TimelineOptions{Page: domain.PageRequest{Limit: 10}}).Return(someTimeline, nil)
+
+		req, err := http.NewRequest(http.MethodGet, getArticles+"?feed=some-id&feed=https://some-feed-url&limit=10", nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.GetTimeline(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		bytes, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		var timeline domain.Timeline
+		err = json.Unmarshal(bytes, &timeline)
+		require.NoError(t, err)
+
+		assert.Equal(t, someTimeline, timeline)
+	})
+
+	t.Run("should return a bad request if limit is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		req, err := http.NewRequest(http.MethodGet, getArticles+"?limit=-1", nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.GetTimeline(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should return a bad request if too many feeds are given", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		query := url.Values{}
+		for i := 0; i < 51; i++ {
+			query.Add("feed", fmt.Sprintf("https://some-feed-url/%d", i))
+		}
+		req, err := http.NewRequest(http.MethodGet, getArticles+"?"+query.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.GetTimeline(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should return a internal server error if service layer fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		handler := NewHandler(mockService, "")
+
+		mockService.EXPECT().GetTimeline(gomock.Any(), gomock.Any(), gomock.Any()).Return(domain.Timeline{}, assert.AnError)
+
+		req, err := http.NewRequest(http.MethodGet, getArticles, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.GetTimeline(w, req)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	})
+}
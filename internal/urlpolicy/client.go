@@ -0,0 +1,46 @@
+package urlpolicy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// NewClient is a constructor for an http client that enforces the policy on every connection and redirect.
+// Addresses are checked after they are resolved, as the connection is made, so a host name that resolves to a public address
+// when the URL is checked and a private one when it is fetched is still blocked.
+func NewClient(policy *Policy) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return blocked("address %s could not be checked", host)
+			}
+			return policy.CheckIP(ip)
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would make the connection on our behalf without the address being checked
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > policy.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", policy.MaxRedirects)
+			}
+			return policy.CheckURL(req.URL.String())
+		},
+	}
+}
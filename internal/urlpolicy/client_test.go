@@ -0,0 +1,87 @@
+package urlpolicy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"news-app/internal/domain"
+	"news-app/internal/parser"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewClient(t *testing.T) {
+	someFeed := `<rss version="2.0"><channel><title>some-title</title></channel></rss>`
+
+	t.Run("should block addresses that are not public when they are dialled", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("the request should not have been sent")
+		}))
+		defer server.Close()
+
+		policy := &Policy{Schemes: []string{"http"}, MaxRedirects: 5}
+		p := parser.NewParser(10*time.Second, parser.NewFetcher(NewClient(policy), "some-user-agent", 1<<20, nil), gofeed.NewParser())
+
+		// localhost is a host name so it passes CheckURL, it is only caught once it resolves to a loopback address
+		feedURL := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+		require.NoError(t, policy.CheckURL(feedURL))
+
+		_, err := p.Parse(context.Background(), feedURL)
+		assert.ErrorIs(t, err, domain.ErrBlockedURL)
+		assert.NotErrorIs(t, err, domain.ErrFeedUnreachable)
+	})
+	t.Run("should fetch private addresses when they are allowed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, someFeed)
+		}))
+		defer server.Close()
+
+		policy := &Policy{Schemes: []string{"http"}, AllowPrivateNetworks: true, MaxRedirects: 5}
+		p := parser.NewParser(10*time.Second, parser.NewFetcher(NewClient(policy), "some-user-agent", 1<<20, nil), gofeed.NewParser())
+
+		feed, err := p.Parse(context.Background(), server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, "some-title", feed.Title)
+	})
+	t.Run("should stop following redirects after the maximum", func(t *testing.T) {
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var hops int
+			fmt.Sscanf(r.URL.Query().Get("hops"), "%d", &hops)
+			if hops > 0 {
+				http.Redirect(w, r, fmt.Sprintf("%s/?hops=%d", server.URL, hops-1), http.StatusFound)
+				return
+			}
+			fmt.Fprint(w, someFeed)
+		}))
+		defer server.Close()
+
+		policy := &Policy{Schemes: []string{"http"}, AllowPrivateNetworks: true, MaxRedirects: 2}
+		p := parser.NewParser(10*time.Second, parser.NewFetcher(NewClient(policy), "some-user-agent", 1<<20, nil), gofeed.NewParser())
+
+		_, err := p.Parse(context.Background(), server.URL+"/?hops=2")
+		assert.NoError(t, err)
+
+		_, err = p.Parse(context.Background(), server.URL+"/?hops=3")
+		assert.ErrorIs(t, err, domain.ErrFeedUnreachable)
+	})
+	t.Run("should check where redirects lead against the policy", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "https://denied-host.com/rss", http.StatusFound)
+		}))
+		defer server.Close()
+
+		policy := &Policy{Schemes: []string{"http", "https"}, AllowPrivateNetworks: true, DeniedHosts: []string{"denied-host.com"}, MaxRedirects: 5}
+		p := parser.NewParser(10*time.Second, parser.NewFetcher(NewClient(policy), "some-user-agent", 1<<20, nil), gofeed.NewParser())
+
+		_, err := p.Parse(context.Background(), server.URL)
+		assert.ErrorIs(t, err, domain.ErrBlockedURL)
+	})
+}
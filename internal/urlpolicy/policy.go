@@ -0,0 +1,154 @@
+package urlpolicy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"news-app/internal/domain"
+	"news-app/internal/parser"
+)
+
+// blockedNetworks are ranges that are not private in the sense of net.IP.IsPrivate but still must not be reachable through a feed URL
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",       // this network
+	"100.64.0.0/10",   // carrier grade NAT
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // documentation
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // documentation
+	"203.0.113.0/24",  // documentation
+	"240.0.0.0/4",     // reserved, includes broadcast
+	"64:ff9b::/96",    // NAT64, can embed any IPv4 address
+	"64:ff9b:1::/48",  // local use NAT64
+	"2001:db8::/32",   // documentation
+	"100::/64",        // discard
+)
+
+// Policy decides which feed URLs may be fetched
+type Policy struct {
+	// Schemes are the URL schemes feeds may use
+	Schemes []string
+	// AllowPrivateNetworks allows loopback, private and link local addresses, it is only meant for local development
+	AllowPrivateNetworks bool
+	// AllowedHosts limits feeds to these hosts and their subdomains when it is not empty
+	AllowedHosts []string
+	// DeniedHosts blocks these hosts and their subdomains, it takes precedence over AllowedHosts
+	DeniedHosts []string
+	// MaxRedirects is the number of redirects followed before a fetch fails
+	MaxRedirects int
+}
+
+// CheckURL returns an error wrapping domain.ErrBlockedURL if the policy does not allow the URL.
+// Addresses are checked again when they are dialled since a host name can resolve differently by then.
+func (p *Policy) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return &domain.FetchError{Kind: domain.ErrInvalidFeedURL, Err: err}
+	}
+
+	if !p.allowsScheme(u.Scheme) {
+		return blocked("scheme %q is not allowed", u.Scheme)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return &domain.FetchError{Kind: domain.ErrInvalidFeedURL, Err: fmt.Errorf("%q has no host", rawURL)}
+	}
+	if matchesHost(p.DeniedHosts, host) {
+		return blocked("host %s is denied", host)
+	}
+	if len(p.AllowedHosts) > 0 && !matchesHost(p.AllowedHosts, host) {
+		return blocked("host %s is not allowed", host)
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return p.CheckIP(ip)
+	}
+
+	return nil
+}
+
+// CheckIP returns an error wrapping domain.ErrBlockedURL if the address is not publicly routable and private networks are not allowed
+func (p *Policy) CheckIP(ip net.IP) error {
+	if p.AllowPrivateNetworks {
+		return nil
+	}
+
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return blocked("address %s is not public", ip)
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return blocked("address %s is not public", ip)
+		}
+	}
+
+	return nil
+}
+
+func (p *Policy) allowsScheme(scheme string) bool {
+	for _, s := range p.Schemes {
+		if strings.EqualFold(s, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesHost reports whether host is one of hosts or a subdomain of one
+func matchesHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		h = strings.TrimSuffix(strings.ToLower(h), ".")
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func blocked(format string, args ...interface{}) error {
+	return &domain.FetchError{Kind: domain.ErrBlockedURL, Err: fmt.Errorf(format, args...)}
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// guardedParser rejects URLs the policy does not allow before they reach the parser
+type guardedParser struct {
+	parser.UniversalParser
+	policy *Policy
+}
+
+// Guard wraps a parser so URLs the policy does not allow are never fetched.
+// The parser should use a client from NewClient so the addresses it connects to are checked as well.
+func Guard(p parser.UniversalParser, policy *Policy) parser.UniversalParser {
+	return &guardedParser{
+		UniversalParser: p,
+		policy:          policy,
+	}
+}
+
+func (p *guardedParser) Parse(ctx context.Context, feedURL string) (domain.Feed, error) {
+	if err := p.policy.CheckURL(feedURL); err != nil {
+		return domain.Feed{}, fmt.Errorf("failed to parse url: %w", err)
+	}
+
+	return p.UniversalParser.Parse(ctx, feedURL)
+}
@@ -0,0 +1,127 @@
+package urlpolicy
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"news-app/internal/domain"
+	"news-app/internal/parser"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Policy_CheckURL(t *testing.T) {
+	t.Run("should allow public http and https urls", func(t *testing.T) {
+		policy := &Policy{Schemes: []string{"http", "https"}}
+
+		for _, url := range []string{"https://some-host.com/rss", "HTTP://some-host.com:8080/rss", "https://93.184.216.34/rss"} {
+			assert.NoError(t, policy.CheckURL(url), url)
+		}
+	})
+	t.Run("should block schemes that are not allowed", func(t *testing.T) {
+		policy := &Policy{Schemes: []string{"https"}}
+
+		for _, url := range []string{"http://some-host.com/rss", "file:///etc/passwd", "gopher://some-host.com"} {
+			assert.ErrorIs(t, policy.CheckURL(url), domain.ErrBlockedURL, url)
+		}
+	})
+	t.Run("should block addresses that are not public", func(t *testing.T) {
+		policy := &Policy{Schemes: []string{"http"}}
+
+		for _, url := range []string{
+			"http://127.0.0.1/rss",
+			"http://10.0.0.1/rss",
+			"http://169.254.169.254/latest/meta-data",
+			"http://[::1]/rss",
+			"http://[::ffff:127.0.0.1]/rss",
+			"http://0.0.0.0/rss",
+		} {
+			assert.ErrorIs(t, policy.CheckURL(url), domain.ErrBlockedURL, url)
+		}
+
+		policy.AllowPrivateNetworks = true
+		assert.NoError(t, policy.CheckURL("http://127.0.0.1/rss"))
+	})
+	t.Run("should apply the allowed and denied hosts to subdomains", func(t *testing.T) {
+		policy := &Policy{
+			Schemes:      []string{"https"},
+			AllowedHosts: []string{"some-host.com", "other-host.com"},
+			DeniedHosts:  []string{"internal.some-host.com"},
+		}
+
+		assert.NoError(t, policy.CheckURL("https://some-host.com/rss"))
+		assert.NoError(t, policy.CheckURL("https://news.other-host.com/rss"))
+		assert.NoError(t, policy.CheckURL("https://SOME-HOST.COM./rss"))
+		assert.ErrorIs(t, policy.CheckURL("https://internal.some-host.com/rss"), domain.ErrBlockedURL)
+		assert.ErrorIs(t, policy.CheckURL("https://api.internal.some-host.com/rss"), domain.ErrBlockedURL)
+		assert.ErrorIs(t, policy.CheckURL("https://another-host.com/rss"), domain.ErrBlockedURL)
+		assert.ErrorIs(t, policy.CheckURL("https://evil-some-host.com/rss"), domain.ErrBlockedURL)
+	})
+	t.Run("should return an invalid url for urls without a host", func(t *testing.T) {
+		policy := &Policy{Schemes: []string{"https"}}
+
+		assert.ErrorIs(t, policy.CheckURL("https:///rss"), domain.ErrInvalidFeedURL)
+		assert.ErrorIs(t, policy.CheckURL("https://some-host.com/%zz"), domain.ErrInvalidFeedURL)
+	})
+}
+
+func Test_Policy_CheckIP(t *testing.T) {
+	t.Run("should only allow publicly routable addresses", func(t *testing.T) {
+		policy := &Policy{}
+
+		for ip, allowed := range map[string]bool{
+			"93.184.216.34":        true,
+			"2606:2800:220:1::":    true,
+			"127.0.0.1":            false,
+			"10.1.2.3":             false,
+			"172.16.0.1":           false,
+			"192.168.1.1":          false,
+			"169.254.169.254":      false,
+			"100.64.0.1":           false,
+			"0.0.0.0":              false,
+			"224.0.0.1":            false,
+			"255.255.255.255":      false,
+			"::1":                  false,
+			"::":                   false,
+			"fe80::1":              false,
+			"fd00::1":              false,
+			"::ffff:10.0.0.1":      false,
+			"64:ff9b::a9fe:a9fe":   false,
+			"ff02::1":              false,
+			"::ffff:93.184.216.34": true,
+		} {
+			err := policy.CheckIP(net.ParseIP(ip))
+			if allowed {
+				assert.NoError(t, err, ip)
+			} else {
+				assert.ErrorIs(t, err, domain.ErrBlockedURL, ip)
+			}
+		}
+	})
+}
+
+func Test_Guard(t *testing.T) {
+	t.Run("should not fetch urls the policy blocks", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		guarded := Guard(mockParser, &Policy{Schemes: []string{"https"}})
+
+		feed, err := guarded.Parse(context.Background(), "http://169.254.169.254/latest/meta-data")
+		assert.ErrorIs(t, err, domain.ErrBlockedURL)
+		assert.Empty(t, feed)
+	})
+	t.Run("should fetch urls the policy allows", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockParser := parser.NewMockUniversalParser(ctrl)
+		guarded := Guard(mockParser, &Policy{Schemes: []string{"https"}})
+
+		someFeed := domain.Feed{Title: "some-title"}
+		mockParser.EXPECT().Parse(gomock.Any(), "https://some-host.com/rss").Return(someFeed, nil)
+
+		feed, err := guarded.Parse(context.Background(), "https://some-host.com/rss")
+		assert.NoError(t, err)
+		assert.Equal(t, someFeed, feed)
+	})
+}